@@ -1,32 +1,194 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"fourthfloor/internal/cache"
 	"fourthfloor/internal/config"
 	"fourthfloor/internal/handlers"
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/scheduler"
 	"fourthfloor/internal/service"
+	"fourthfloor/internal/storage"
 	"fourthfloor/internal/twitch"
+	"fourthfloor/internal/watchlist"
 	"log"
+	"maps"
 	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
+// shutdownTimeout bounds how long in-flight requests are given to finish on shutdown
+const shutdownTimeout = 15 * time.Second
+
 func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves the API until SIGINT or SIGTERM, then shuts down gracefully:
+// in-flight requests are given shutdownTimeout to finish before the poller,
+// validator and snapshot store are stopped
+func run() error {
 	cfg := config.LoadEnv(".env")
 
 	if cfg.ClientID == "" || cfg.ClientSecret == "" {
-		log.Fatal("twitch credentials missing")
+		return errors.New("twitch credentials missing")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := []func(*twitch.TwitchAPIClient){twitch.WithTimeout(cfg.HTTPTimeout)}
+	if cfg.AuthURL != "" {
+		opts = append(opts, twitch.WithAuthURL(cfg.AuthURL))
+	}
+	if cfg.HelixURL != "" {
+		opts = append(opts,
+			twitch.WithBaseURL(cfg.HelixURL+"/videos"),
+			twitch.WithUsersURL(cfg.HelixURL+"/users"),
+		)
 	}
 
-	twitchClient := twitch.NewTwitchAPIClient(cfg.ClientID, cfg.ClientSecret)
+	twitchClient := twitch.NewTwitchAPIClient(cfg.ClientID, cfg.ClientSecret, opts...)
+	twitchClient.StartValidator(ctx, twitch.DefaultValidateInterval)
+	defer twitchClient.StopValidator()
+
+	retention := storage.RetentionPolicy{Raw: cfg.RetentionRaw, Hourly: cfg.RetentionHourly}
+	if err := retention.Validate(); err != nil {
+		return err
+	}
+
+	watched, err := watchlist.Open(cfg.WatchlistFile)
+	if err != nil {
+		return err
+	}
+
+	var (
+		client twitch.TwitchAPIClientInterface = twitchClient
+		bolt   *storage.BoltStore
+	)
+	if cfg.SnapshotDB != "" {
+		if bolt, err = storage.Open(cfg.SnapshotDB); err != nil {
+			return err
+		}
+		defer bolt.Close()
+		client = storage.NewRecordingClient(client, bolt)
+
+		compaction := scheduler.NewCompactionJob(bolt, retention, scheduler.WithCompactInterval(cfg.CompactInterval))
+		compaction.Start(ctx)
+		defer compaction.Stop()
+
+		poller := scheduler.NewPoller(twitchClient, bolt, scheduler.WithInterval(cfg.PollInterval))
+		watched.OnChange(func(entries []model.WatchlistEntry) {
+			poller.SetTargets(pollTargets(cfg.ChannelIntervals(), entries))
+		})
+		poller.SetTargets(pollTargets(cfg.ChannelIntervals(), watched.List()))
+		poller.Start(ctx)
+		defer poller.Stop()
+	} else {
+		log.Print("SNAPSHOT_DB is empty, TWITCH_CHANNEL_ID and the watchlist will not be polled")
+	}
+	if cfg.CacheTTL > 0 {
+		cacheOpts, err := cacheOptions(cfg)
+		if err != nil {
+			return err
+		}
+		client = cache.NewClient(client, cacheOpts...)
+	}
 
-	videoService := &service.VideoService{TwitchClient: twitchClient}
+	videoService := &service.VideoService{TwitchClient: client}
+	if bolt != nil {
+		videoService.Snapshots = bolt
+	}
 
 	handler := &handlers.VideoHandler{Service: videoService}
+	watchlistHandler := &handlers.WatchlistHandler{Watchlist: watched, Users: client}
 
 	r := mux.NewRouter()
 	r.HandleFunc("/streamers/{channel_id}/videos", handler.GetStreamerVideosHandler).Methods("GET")
+	r.HandleFunc("/streamers/by-login/{login}/videos", handler.GetStreamerVideosByLoginHandler).Methods("GET")
+	r.HandleFunc("/streamers/{channel_id}/videos/growth", handler.GetStreamerVideoGrowthHandler).Methods("GET")
+	r.HandleFunc("/compare", handler.CompareChannelsHandler).Methods("GET")
+	if bolt != nil {
+		historyHandler := &handlers.HistoryHandler{Service: &service.HistoryService{Store: bolt}}
+		storageHandler := &handlers.StorageHandler{Store: bolt}
+		r.HandleFunc("/videos/{video_id}/history", historyHandler.GetVideoHistoryHandler).Methods("GET")
+		r.HandleFunc("/storage/stats", storageHandler.GetStorageStatsHandler).Methods("GET")
+	}
+	r.HandleFunc("/watchlist", watchlistHandler.ListWatchlistHandler).Methods("GET")
+	r.HandleFunc("/watchlist", watchlistHandler.AddWatchlistHandler).Methods("POST")
+	r.HandleFunc("/watchlist/{channel_id}", watchlistHandler.GetWatchlistEntryHandler).Methods("GET")
+	r.HandleFunc("/watchlist/{channel_id}", watchlistHandler.UpdateWatchlistHandler).Methods("PATCH")
+	r.HandleFunc("/watchlist/{channel_id}", watchlistHandler.RemoveWatchlistHandler).Methods("DELETE")
+	r.NotFoundHandler = http.HandlerFunc(handlers.NotFoundHandler)
+	r.MethodNotAllowedHandler = http.HandlerFunc(handlers.MethodNotAllowedHandler)
+
+	srv := &http.Server{Addr: ":" + cfg.Port, Handler: handlers.RequestID(r)}
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server running on :%s\n", cfg.Port)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Print("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
 
-	log.Printf("Server running on :%s\n", cfg.Port)
-	log.Fatal(http.ListenAndServe(":"+cfg.Port, r))
+// pollTargets returns a poller target for each configured channel and each
+// watchlist entry at its own interval, zero meaning the default, the watchlist
+// taking precedence for channels in both
+func pollTargets(channels map[string]time.Duration, entries []model.WatchlistEntry) []scheduler.Target {
+	targets := make(map[string]scheduler.Target, len(channels)+len(entries))
+	for id, interval := range channels {
+		targets[id] = scheduler.Target{ChannelID: id, Interval: interval}
+	}
+	for _, e := range entries {
+		targets[e.ChannelID] = scheduler.Target{ChannelID: e.ChannelID, Interval: time.Duration(e.IntervalSeconds) * time.Second}
+	}
+	return slices.Collect(maps.Values(targets))
+}
+
+// cacheOptions builds the response cache options from config
+func cacheOptions(cfg config.Config) ([]func(*cache.Client), error) {
+	opts := []func(*cache.Client){
+		cache.WithTTL(cfg.CacheTTL),
+		cache.WithStaleTTL(cfg.CacheStaleTTL),
+	}
+	if cfg.CacheNamespace != "" {
+		opts = append(opts, cache.WithNamespace(cfg.CacheNamespace))
+	}
+
+	switch cfg.CacheBackend {
+	case "redis":
+		backend := cache.NewRedisBackend(cfg.RedisAddr,
+			cache.WithRedisPassword(cfg.RedisPassword),
+			cache.WithRedisDB(cfg.RedisDB),
+		)
+		if err := backend.Ping(context.Background()); err != nil {
+			log.Printf("redis cache at %s unreachable, requests will miss until it is back: %v", cfg.RedisAddr, err)
+		}
+		opts = append(opts, cache.WithBackend(backend))
+	case "memory":
+		opts = append(opts, cache.WithBackend(cache.NewMemoryBackend(cfg.CacheMaxEntries)))
+	default:
+		return nil, fmt.Errorf("unknown CACHE_BACKEND %q", cfg.CacheBackend)
+	}
+	return opts, nil
 }
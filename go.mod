@@ -5,4 +5,7 @@ go 1.24
 require (
 	github.com/gorilla/mux v1.8.1
 	github.com/joho/godotenv v1.5.1
+	go.etcd.io/bbolt v1.4.3
 )
+
+require golang.org/x/sys v0.29.0 // indirect
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Backend stores serialized responses for the Client. Implementations may be
+// shared between replicas; values expire after the TTL they were set with.
+type Backend interface {
+	// Get returns the value stored for key, reporting false if there is none.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores value for key, expiring it after ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// MemoryBackend in-process Backend holding at most maxEntries values, evicting
+// the least recently used.
+type MemoryBackend struct {
+	entries *lru
+	now     func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewMemoryBackend creates a MemoryBackend holding at most maxEntries values,
+// unbounded if maxEntries <= 0.
+func NewMemoryBackend(maxEntries int) *MemoryBackend {
+	return &MemoryBackend{entries: newLRU(maxEntries), now: time.Now}
+}
+
+// Get returns the value stored for key if it has not expired.
+func (b *MemoryBackend) Get(_ context.Context, key string) ([]byte, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries.get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	if !b.now().Before(e.expires) {
+		b.entries.remove(key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+// Set stores value for key until ttl has passed.
+func (b *MemoryBackend) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries.set(entry{key: key, value: value, expires: b.now().Add(ttl)})
+	return nil
+}
+
+// Len returns the number of stored values, including expired ones not yet evicted.
+func (b *MemoryBackend) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.entries.len()
+}
@@ -0,0 +1,271 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/twitch"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultTTL is how long a cached response is served as fresh
+	defaultTTL = time.Minute
+
+	// defaultStaleTTL is how long past its TTL a response is still served while
+	// being refreshed in the background
+	defaultStaleTTL = 5 * time.Minute
+
+	// defaultMaxEntries bounds the number of responses in the default memory backend
+	defaultMaxEntries = 1000
+
+	// DefaultNamespace prefixes every key, versioned so that a change to the
+	// cached model types does not read entries written by older replicas.
+	DefaultNamespace = "twitch-stats:v1:"
+)
+
+// Client caching TwitchAPIClientInterface wrapping another implementation.
+// Responses are keyed on every request parameter, stored as JSON in a Backend
+// and served for TTL; after that, for up to StaleTTL more, the stale response
+// is served while a single background call refreshes it. Concurrent identical
+// calls share one upstream call, which is cancelled only once every caller
+// waiting on it has gone. Errors are not cached, and a failing backend is
+// treated as a miss.
+type Client struct {
+	next      twitch.TwitchAPIClientInterface
+	backend   Backend
+	namespace string
+	ttl       time.Duration
+	staleTTL  time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex // protects calls
+	calls map[string]*call
+}
+
+// envelope serialized form of a cached response
+type envelope struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// call upstream call in flight, shared by every caller of the same key
+type call struct {
+	done  chan struct{}
+	value []byte
+	err   error
+
+	cancel     context.CancelFunc
+	waiters    int
+	background bool // stale-while-revalidate refresh, never cancelled
+}
+
+// NewClient creates a Client caching responses from next, by default in a
+// MemoryBackend holding 1000 responses.
+func NewClient(next twitch.TwitchAPIClientInterface, options ...func(*Client)) *Client {
+	c := &Client{
+		next:      next,
+		backend:   NewMemoryBackend(defaultMaxEntries),
+		namespace: DefaultNamespace,
+		ttl:       defaultTTL,
+		staleTTL:  defaultStaleTTL,
+		now:       time.Now,
+		calls:     make(map[string]*call),
+	}
+
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+// WithBackend sets where responses are stored.
+func WithBackend(b Backend) func(*Client) {
+	return func(c *Client) { c.backend = b }
+}
+
+// WithNamespace sets the prefix of every key, separating deployments sharing a backend.
+func WithNamespace(ns string) func(*Client) {
+	return func(c *Client) { c.namespace = ns }
+}
+
+// WithTTL sets how long responses are served as fresh.
+func WithTTL(ttl time.Duration) func(*Client) {
+	return func(c *Client) { c.ttl = ttl }
+}
+
+// WithStaleTTL sets how long past their TTL responses are served while being
+// refreshed. Zero disables stale-while-revalidate.
+func WithStaleTTL(ttl time.Duration) func(*Client) {
+	return func(c *Client) { c.staleTTL = ttl }
+}
+
+// FetchVideos returns the cached videos for the call, fetching them on a miss.
+func (c *Client) FetchVideos(ctx context.Context, channelID string, limit int, filter model.VideoFilter) ([]model.Video, error) {
+	params := filterParams(channelID, filter)
+	params.Set("limit", strconv.Itoa(limit))
+
+	return cached(ctx, c, "videos?"+params.Encode(), func(ctx context.Context) ([]model.Video, error) {
+		return c.next.FetchVideos(ctx, channelID, limit, filter)
+	})
+}
+
+// FetchVideoPage returns the cached page for the call, fetching it on a miss.
+func (c *Client) FetchVideoPage(ctx context.Context, channelID string, first int, filter model.VideoFilter, cursor string) (model.VideoResponse, error) {
+	params := filterParams(channelID, filter)
+	params.Set("first", strconv.Itoa(first))
+	params.Set("after", cursor)
+
+	return cached(ctx, c, "page?"+params.Encode(), func(ctx context.Context) (model.VideoResponse, error) {
+		return c.next.FetchVideoPage(ctx, channelID, first, filter, cursor)
+	})
+}
+
+// FetchUserByLogin returns the cached user for login, fetching it on a miss.
+func (c *Client) FetchUserByLogin(ctx context.Context, login string) (model.User, error) {
+	login = strings.ToLower(login)
+
+	return cached(ctx, c, "user?"+url.Values{"login": {login}}.Encode(), func(ctx context.Context) (model.User, error) {
+		return c.next.FetchUserByLogin(ctx, login)
+	})
+}
+
+// cached returns the response cached for key, decoded into a fresh T so callers
+// never share it, calling fetch on a miss
+func cached[T any](ctx context.Context, c *Client, key string, fetch func(context.Context) (T, error)) (T, error) {
+	var v T
+
+	data, err := c.get(ctx, c.namespace+key, func(ctx context.Context) ([]byte, error) {
+		v, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(v)
+	})
+	if err != nil {
+		return v, err
+	}
+
+	err = json.Unmarshal(data, &v)
+	return v, err
+}
+
+// get returns the value cached for key, calling fetch on a miss. A stale value
+// is returned as is and refreshed in the background.
+func (c *Client) get(ctx context.Context, key string, fetch func(context.Context) ([]byte, error)) ([]byte, error) {
+	if env, ok := c.lookup(ctx, key); ok {
+		age := c.now().Sub(env.StoredAt)
+		if age < c.ttl {
+			return env.Value, nil
+		}
+		if age < c.ttl+c.staleTTL {
+			c.mu.Lock()
+			if _, inFlight := c.calls[key]; !inFlight {
+				c.start(ctx, key, fetch).background = true
+			}
+			c.mu.Unlock()
+			return env.Value, nil
+		}
+	}
+
+	c.mu.Lock()
+	cl, ok := c.calls[key]
+	if !ok {
+		cl = c.start(ctx, key, fetch)
+	}
+	cl.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		c.mu.Lock()
+		cl.waiters--
+		if cl.waiters == 0 && !cl.background {
+			// later callers start afresh rather than join the cancelled call
+			cl.cancel()
+			if c.calls[key] == cl {
+				delete(c.calls, key)
+			}
+		}
+		c.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// lookup reads the envelope stored for key, reporting backend and decoding
+// failures as a miss
+func (c *Client) lookup(ctx context.Context, key string) (envelope, bool) {
+	data, ok, err := c.backend.Get(ctx, key)
+	if err != nil {
+		log.Printf("cache get %s: %v", key, err)
+		return envelope{}, false
+	}
+	if !ok {
+		return envelope{}, false
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		log.Printf("cache decode %s: %v", key, err)
+		return envelope{}, false
+	}
+	return env, true
+}
+
+// start calls fetch for key in the background, storing a successful result for
+// TTL plus StaleTTL. The call runs detached from ctx's cancellation so that it
+// can outlive the caller that started it. c.mu must be held.
+func (c *Client) start(ctx context.Context, key string, fetch func(context.Context) ([]byte, error)) *call {
+	fetchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	cl := &call{done: make(chan struct{}), cancel: cancel}
+	c.calls[key] = cl
+
+	go func() {
+		defer cancel()
+
+		value, err := fetch(fetchCtx)
+		if err == nil {
+			c.store(fetchCtx, key, value)
+		}
+
+		c.mu.Lock()
+		cl.value, cl.err = value, err
+		if c.calls[key] == cl {
+			delete(c.calls, key)
+		}
+		c.mu.Unlock()
+
+		close(cl.done)
+	}()
+
+	return cl
+}
+
+// store writes value for key to the backend, logging failures
+func (c *Client) store(ctx context.Context, key string, value []byte) {
+	data, err := json.Marshal(envelope{StoredAt: c.now(), Value: value})
+	if err == nil {
+		err = c.backend.Set(ctx, key, data, c.ttl+c.staleTTL)
+	}
+	if err != nil {
+		log.Printf("cache set %s: %v", key, err)
+	}
+}
+
+// filterParams request parameters shared by the video calls
+func filterParams(channelID string, filter model.VideoFilter) url.Values {
+	params := url.Values{}
+	params.Set("channel", channelID)
+	params.Set("type", string(filter.Type))
+	params.Set("period", string(filter.Period))
+	params.Set("sort", string(filter.Sort))
+	params.Set("language", filter.Language)
+	return params
+}
@@ -0,0 +1,323 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fourthfloor/internal/model"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ---- Mocks ----
+
+// mockTwitchClient counts calls per channel and returns videos whose title
+// carries the call number, optionally blocking until released.
+type mockTwitchClient struct {
+	mu      sync.Mutex
+	calls   int
+	err     error
+	release chan struct{} // if set, calls block until closed or cancelled
+	ctxErr  chan error    // receives ctx.Err() of calls cancelled while blocked
+}
+
+func (m *mockTwitchClient) FetchVideos(ctx context.Context, channelID string, limit int, filter model.VideoFilter) ([]model.Video, error) {
+	m.mu.Lock()
+	m.calls++
+	n := m.calls
+	m.mu.Unlock()
+
+	if m.release != nil {
+		select {
+		case <-m.release:
+		case <-ctx.Done():
+			m.ctxErr <- ctx.Err()
+			return nil, ctx.Err()
+		}
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []model.Video{{Title: channelID + "#" + strconv.Itoa(n)}}, nil
+}
+
+func (m *mockTwitchClient) FetchVideoPage(ctx context.Context, channelID string, first int, filter model.VideoFilter, cursor string) (model.VideoResponse, error) {
+	videos, err := m.FetchVideos(ctx, channelID, first, filter)
+	return model.VideoResponse{Data: videos, Pagination: model.Pagination{Cursor: cursor + "+"}}, err
+}
+
+func (m *mockTwitchClient) FetchUserByLogin(ctx context.Context, login string) (model.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	return model.User{ID: strconv.Itoa(m.calls), Login: login}, m.err
+}
+
+func (m *mockTwitchClient) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// newTestClient returns a Client backed by a MemoryBackend holding maxEntries,
+// both on a fake clock
+func newTestClient(next *mockTwitchClient, maxEntries int, options ...func(*Client)) (*Client, *MemoryBackend, *time.Time) {
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	backend := NewMemoryBackend(maxEntries)
+	backend.now = clock
+
+	c := NewClient(next, append(options, WithBackend(backend))...)
+	c.now = clock
+	return c, backend, &now
+}
+
+// waitIdle waits for background calls to finish
+func waitIdle(t *testing.T, c *Client) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		n := len(c.calls)
+		c.mu.Unlock()
+		if n == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("background call did not finish")
+}
+
+// failingBackend Backend whose every call fails
+type failingBackend struct{}
+
+func (failingBackend) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("backend down")
+}
+
+func (failingBackend) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("backend down")
+}
+
+// ---- Tests ----
+
+func TestClientCachesByParameters(t *testing.T) {
+	next := &mockTwitchClient{}
+	c, _, _ := newTestClient(next, 0)
+	ctx := context.Background()
+
+	calls := []func() error{
+		func() error { _, err := c.FetchVideos(ctx, "a", 5, model.VideoFilter{}); return err },
+		func() error { _, err := c.FetchVideos(ctx, "a", 5, model.VideoFilter{}); return err }, // hit
+		func() error { _, err := c.FetchVideos(ctx, "a", 6, model.VideoFilter{}); return err },
+		func() error { _, err := c.FetchVideos(ctx, "b", 5, model.VideoFilter{}); return err },
+		func() error {
+			_, err := c.FetchVideos(ctx, "a", 5, model.VideoFilter{Type: model.VideoTypeArchive})
+			return err
+		},
+		func() error { _, err := c.FetchVideoPage(ctx, "a", 5, model.VideoFilter{}, ""); return err },
+		func() error { _, err := c.FetchVideoPage(ctx, "a", 5, model.VideoFilter{}, "abc"); return err },
+		func() error { _, err := c.FetchVideoPage(ctx, "a", 5, model.VideoFilter{}, "abc"); return err }, // hit
+		func() error { _, err := c.FetchUserByLogin(ctx, "TwitchDev"); return err },
+		func() error { _, err := c.FetchUserByLogin(ctx, "twitchdev"); return err }, // hit
+	}
+	for i, call := range calls {
+		if err := call(); err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		waitIdle(t, c)
+	}
+
+	if got := next.callCount(); got != 7 {
+		t.Errorf("wanted 7 upstream calls, got %d", got)
+	}
+}
+
+func TestClientExpiry(t *testing.T) {
+	next := &mockTwitchClient{}
+	c, _, now := newTestClient(next, 0, WithTTL(time.Minute), WithStaleTTL(time.Minute))
+	ctx := context.Background()
+
+	fetch := func() string {
+		videos, err := c.FetchVideos(ctx, "a", 1, model.VideoFilter{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return videos[0].Title
+	}
+
+	if got := fetch(); got != "a#1" {
+		t.Fatalf("wanted first fetch, got %s", got)
+	}
+
+	// stale: served as is while refreshed in the background
+	*now = now.Add(90 * time.Second)
+	if got := fetch(); got != "a#1" {
+		t.Errorf("wanted stale response, got %s", got)
+	}
+	waitIdle(t, c)
+	if got := fetch(); got != "a#2" {
+		t.Errorf("wanted refreshed response, got %s", got)
+	}
+
+	// past the stale window: fetched before returning
+	*now = now.Add(3 * time.Minute)
+	if got := fetch(); got != "a#3" {
+		t.Errorf("wanted expired response refetched, got %s", got)
+	}
+	if got := next.callCount(); got != 3 {
+		t.Errorf("wanted 3 upstream calls, got %d", got)
+	}
+}
+
+func TestClientLRUEviction(t *testing.T) {
+	next := &mockTwitchClient{}
+	c, backend, _ := newTestClient(next, 2)
+	ctx := context.Background()
+
+	for _, ch := range []string{"a", "b", "a", "c", "a", "b"} {
+		if _, err := c.FetchVideos(ctx, ch, 1, model.VideoFilter{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// a, b, c fetched; a stays recently used so c evicts b, which is fetched again
+	if got := next.callCount(); got != 4 {
+		t.Errorf("wanted 4 upstream calls, got %d", got)
+	}
+	if got := backend.Len(); got != 2 {
+		t.Errorf("wanted 2 entries, got %d", got)
+	}
+}
+
+func TestClientSharesConcurrentCalls(t *testing.T) {
+	next := &mockTwitchClient{release: make(chan struct{})}
+	c, _, _ := newTestClient(next, 0)
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			videos, err := c.FetchVideos(context.Background(), "a", 1, model.VideoFilter{})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			results[i] = videos[0].Title
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	if got := next.callCount(); got != 1 {
+		t.Errorf("wanted 1 upstream call, got %d", got)
+	}
+	for _, r := range results {
+		if r != "a#1" {
+			t.Errorf("wanted every caller to get the shared result, got %v", results)
+			break
+		}
+	}
+}
+
+func TestClientDoesNotCacheErrors(t *testing.T) {
+	next := &mockTwitchClient{err: errors.New("fetch failed")}
+	c, _, _ := newTestClient(next, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.FetchVideos(ctx, "a", 1, model.VideoFilter{}); err == nil {
+			t.Fatal("wanted error, got nil")
+		}
+	}
+	if got := next.callCount(); got != 2 {
+		t.Errorf("wanted every call to reach upstream, got %d", got)
+	}
+}
+
+func TestClientCancelsAbandonedCall(t *testing.T) {
+	next := &mockTwitchClient{release: make(chan struct{}), ctxErr: make(chan error, 1)}
+	c, _, _ := newTestClient(next, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	if _, err := c.FetchVideos(ctx, "a", 1, model.VideoFilter{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted context.Canceled, got %v", err)
+	}
+
+	select {
+	case err := <-next.ctxErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("wanted upstream call cancelled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("upstream call not cancelled after its only caller left")
+	}
+}
+
+func TestClientReturnsCopies(t *testing.T) {
+	next := &mockTwitchClient{}
+	c, _, _ := newTestClient(next, 0)
+	ctx := context.Background()
+
+	videos, _ := c.FetchVideos(ctx, "a", 1, model.VideoFilter{})
+	videos[0].Title = "changed"
+
+	videos, _ = c.FetchVideos(ctx, "a", 1, model.VideoFilter{})
+	if videos[0].Title != "a#1" {
+		t.Errorf("wanted cached videos unaffected by caller changes, got %s", videos[0].Title)
+	}
+}
+
+func TestClientStoresEnvelope(t *testing.T) {
+	next := &mockTwitchClient{}
+	c, backend, now := newTestClient(next, 0, WithNamespace("test:"), WithTTL(time.Minute), WithStaleTTL(time.Minute))
+	ctx := context.Background()
+
+	if _, err := c.FetchUserByLogin(ctx, "twitchdev"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, ok, _ := backend.Get(ctx, "test:user?login=twitchdev")
+	if !ok {
+		t.Fatal("wanted response stored under the namespaced key")
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		t.Fatalf("decode envelope: %v", err)
+	}
+	if !env.StoredAt.Equal(*now) || string(env.Value) != `{"id":"1","login":"twitchdev","display_name":"","type":"","broadcaster_type":"","description":"","profile_image_url":"","offline_image_url":"","created_at":"0001-01-01T00:00:00Z"}` {
+		t.Errorf("unexpected envelope %s", data)
+	}
+
+	// kept for TTL plus the stale window
+	*now = now.Add(2 * time.Minute)
+	if _, ok, _ := backend.Get(ctx, "test:user?login=twitchdev"); ok {
+		t.Error("wanted entry expired after TTL plus stale TTL")
+	}
+}
+
+func TestClientFailingBackend(t *testing.T) {
+	next := &mockTwitchClient{}
+	c := NewClient(next, WithBackend(failingBackend{}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.FetchVideos(context.Background(), "a", 1, model.VideoFilter{}); err != nil {
+			t.Fatalf("wanted backend failure treated as a miss, got %v", err)
+		}
+	}
+	if got := next.callCount(); got != 2 {
+		t.Errorf("wanted every call to reach upstream, got %d", got)
+	}
+}
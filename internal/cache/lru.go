@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"container/list"
+	"time"
+)
+
+// entry cached value with the time it expires
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// lru map of entries bounded to maxEntries, evicting the least recently used.
+// It is not safe for concurrent use.
+type lru struct {
+	maxEntries int
+	order      *list.List // front is most recently used
+	items      map[string]*list.Element
+}
+
+// newLRU creates an lru holding at most maxEntries entries, unbounded if maxEntries <= 0
+func newLRU(maxEntries int) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// get returns the entry for key, marking it most recently used
+func (l *lru) get(key string) (entry, bool) {
+	el, ok := l.items[key]
+	if !ok {
+		return entry{}, false
+	}
+	l.order.MoveToFront(el)
+	return el.Value.(entry), true
+}
+
+// set stores e, evicting the least recently used entry if full
+func (l *lru) set(e entry) {
+	if el, ok := l.items[e.key]; ok {
+		el.Value = e
+		l.order.MoveToFront(el)
+		return
+	}
+
+	l.items[e.key] = l.order.PushFront(e)
+	if l.maxEntries > 0 && l.order.Len() > l.maxEntries {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(entry).key)
+	}
+}
+
+// remove deletes the entry for key if present
+func (l *lru) remove(key string) {
+	if el, ok := l.items[key]; ok {
+		l.order.Remove(el)
+		delete(l.items, key)
+	}
+}
+
+// len returns the number of entries
+func (l *lru) len() int {
+	return l.order.Len()
+}
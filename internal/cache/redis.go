@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultRedisPoolSize is how many idle connections are kept open
+	defaultRedisPoolSize = 8
+
+	// defaultRedisTimeout bounds dialing and each command without a context deadline
+	defaultRedisTimeout = 2 * time.Second
+)
+
+// RedisError error reply from the server, such as "WRONGTYPE ..." or "NOAUTH ...".
+type RedisError string
+
+func (e RedisError) Error() string { return "redis: " + string(e) }
+
+// RedisBackend Backend speaking the Redis protocol (RESP) to a Redis-compatible
+// server, so that replicas share one cache. Connections are pooled; a
+// connection that fails mid-command is discarded.
+type RedisBackend struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+	idle     chan *redisConn
+}
+
+// redisConn connection with its buffered reader
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewRedisBackend creates a RedisBackend for the server at addr (host:port).
+// No connection is made until the first command.
+func NewRedisBackend(addr string, options ...func(*RedisBackend)) *RedisBackend {
+	b := &RedisBackend{
+		addr:    addr,
+		timeout: defaultRedisTimeout,
+		idle:    make(chan *redisConn, defaultRedisPoolSize),
+	}
+
+	for _, opt := range options {
+		opt(b)
+	}
+	return b
+}
+
+// WithRedisPassword authenticates new connections with AUTH.
+func WithRedisPassword(password string) func(*RedisBackend) {
+	return func(b *RedisBackend) { b.password = password }
+}
+
+// WithRedisDB selects the logical database on new connections.
+func WithRedisDB(db int) func(*RedisBackend) {
+	return func(b *RedisBackend) { b.db = db }
+}
+
+// WithRedisPoolSize sets how many idle connections are kept open.
+func WithRedisPoolSize(n int) func(*RedisBackend) {
+	return func(b *RedisBackend) { b.idle = make(chan *redisConn, n) }
+}
+
+// WithRedisTimeout bounds dialing and each command without a context deadline.
+func WithRedisTimeout(d time.Duration) func(*RedisBackend) {
+	return func(b *RedisBackend) { b.timeout = d }
+}
+
+// Get returns the value stored for key.
+func (b *RedisBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := b.do(ctx, "GET", key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return value, true, nil
+}
+
+// Set stores value for key with SET PX, expiring it after ttl (rounded to at least a millisecond).
+func (b *RedisBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := b.do(ctx, "SET", key, string(value), "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	return err
+}
+
+// Delete removes key.
+func (b *RedisBackend) Delete(ctx context.Context, key string) error {
+	_, err := b.do(ctx, "DEL", key)
+	return err
+}
+
+// Ping checks the server is reachable.
+func (b *RedisBackend) Ping(ctx context.Context) error {
+	_, err := b.do(ctx, "PING")
+	return err
+}
+
+// Close closes idle connections. Connections in use are closed when returned.
+func (b *RedisBackend) Close() error {
+	for {
+		select {
+		case c := <-b.idle:
+			c.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do sends a command on a pooled connection and returns its reply: nil, a
+// string for simple strings, []byte for bulk strings, int64 or []any. Error
+// replies are returned as RedisError and leave the connection usable.
+func (b *RedisBackend) do(ctx context.Context, args ...string) (any, error) {
+	c, err := b.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := c.roundTrip(ctx, b.timeout, args)
+
+	var redisErr RedisError
+	if err != nil && !errors.As(err, &redisErr) {
+		c.conn.Close()
+		return nil, err
+	}
+
+	b.release(c)
+	return reply, err
+}
+
+// conn takes an idle connection or dials a new one
+func (b *RedisBackend) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-b.idle:
+		return c, nil
+	default:
+	}
+
+	d := net.Dialer{Timeout: b.timeout}
+	conn, err := d.DialContext(ctx, "tcp", b.addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+
+	var setup [][]string
+	if b.password != "" {
+		setup = append(setup, []string{"AUTH", b.password})
+	}
+	if b.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(b.db)})
+	}
+	for _, args := range setup {
+		if _, err := c.roundTrip(ctx, b.timeout, args); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// release returns c to the pool, closing it if the pool is full
+func (b *RedisBackend) release(c *redisConn) {
+	select {
+	case b.idle <- c:
+	default:
+		c.conn.Close()
+	}
+}
+
+// roundTrip writes a command and reads its reply within ctx's deadline, or timeout if it has none
+func (c *redisConn) roundTrip(ctx context.Context, timeout time.Duration, args []string) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(timeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := c.conn.Write(encodeCommand(args)); err != nil {
+		return nil, err
+	}
+	return readReply(c.r)
+}
+
+// encodeCommand encodes args as a RESP array of bulk strings
+func encodeCommand(args []string) []byte {
+	buf := fmt.Appendf(nil, "*%d\r\n", len(args))
+	for _, a := range args {
+		buf = fmt.Appendf(buf, "$%d\r\n%s\r\n", len(a), a)
+	}
+	return buf
+}
+
+// readReply reads one RESP reply
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, RedisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err // $-1 is a nil reply
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+	}
+}
@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"fourthfloor/internal/model"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ---- Mocks ----
+
+// fakeRedis in-process stand-in speaking enough RESP for RedisBackend: AUTH,
+// SELECT, PING, GET, SET with PX, and DEL. Keys are separate per database.
+type fakeRedis struct {
+	ln       net.Listener
+	password string
+
+	mu      sync.Mutex
+	data    map[string]string
+	expires map[string]time.Time
+	conns   int
+}
+
+// newFakeRedis starts a fakeRedis on a random local port
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	s := &fakeRedis{ln: ln, password: password, data: map[string]string{}, expires: map[string]time.Time{}}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeRedis) addr() string { return s.ln.Addr().String() }
+
+func (s *fakeRedis) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns++
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := s.password == ""
+	db := "0"
+
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, a := range reply.([]any) {
+			args = append(args, string(a.([]byte)))
+		}
+
+		cmd := strings.ToUpper(args[0])
+		if !authed && cmd != "AUTH" {
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+
+		s.mu.Lock()
+		switch cmd {
+		case "AUTH":
+			authed = args[1] == s.password
+			if authed {
+				fmt.Fprint(conn, "+OK\r\n")
+			} else {
+				fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+			}
+		case "SELECT":
+			db = args[1]
+			fmt.Fprint(conn, "+OK\r\n")
+		case "PING":
+			fmt.Fprint(conn, "+PONG\r\n")
+		case "GET":
+			key := db + ":" + args[1]
+			v, ok := s.data[key]
+			if exp, has := s.expires[key]; has && !time.Now().Before(exp) {
+				ok = false
+			}
+			if ok {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(v), v)
+			} else {
+				fmt.Fprint(conn, "$-1\r\n")
+			}
+		case "SET":
+			key := db + ":" + args[1]
+			s.data[key] = args[2]
+			delete(s.expires, key)
+			if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+				ms, _ := strconv.Atoi(args[4])
+				s.expires[key] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			}
+			fmt.Fprint(conn, "+OK\r\n")
+		case "DEL":
+			key := db + ":" + args[1]
+			_, ok := s.data[key]
+			delete(s.data, key)
+			fmt.Fprintf(conn, ":%d\r\n", map[bool]int{true: 1}[ok])
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", args[0])
+		}
+		s.mu.Unlock()
+	}
+}
+
+// ---- Tests ----
+
+func TestRedisBackend(t *testing.T) {
+	srv := newFakeRedis(t, "secret")
+	b := NewRedisBackend(srv.addr(), WithRedisPassword("secret"), WithRedisDB(2))
+	defer b.Close()
+	ctx := context.Background()
+
+	if err := b.Ping(ctx); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+
+	if _, ok, err := b.Get(ctx, "missing"); ok || err != nil {
+		t.Errorf("wanted miss, got ok=%v err=%v", ok, err)
+	}
+
+	value := []byte("binary\r\nsafe \x00 value")
+	if err := b.Set(ctx, "key", value, time.Minute); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	got, ok, err := b.Get(ctx, "key")
+	if !ok || err != nil || string(got) != string(value) {
+		t.Errorf("wanted %q, got %q ok=%v err=%v", value, got, ok, err)
+	}
+
+	if err := b.Delete(ctx, "key"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, ok, _ := b.Get(ctx, "key"); ok {
+		t.Error("wanted key deleted")
+	}
+
+	if err := b.Set(ctx, "short", value, 20*time.Millisecond); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if _, ok, _ := b.Get(ctx, "short"); ok {
+		t.Error("wanted key expired after its TTL")
+	}
+
+	// every command above reused one pooled connection
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.conns != 1 {
+		t.Errorf("wanted 1 pooled connection, got %d", srv.conns)
+	}
+}
+
+func TestRedisBackendErrors(t *testing.T) {
+	srv := newFakeRedis(t, "secret")
+	ctx := context.Background()
+
+	var redisErr RedisError
+	if err := NewRedisBackend(srv.addr(), WithRedisPassword("wrong")).Ping(ctx); !errors.As(err, &redisErr) {
+		t.Errorf("wanted RedisError for a bad password, got %v", err)
+	}
+
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+	if err := NewRedisBackend(addr, WithRedisTimeout(100*time.Millisecond)).Ping(ctx); err == nil {
+		t.Error("wanted error for an unreachable server")
+	}
+}
+
+func TestClientWithRedisBackend(t *testing.T) {
+	srv := newFakeRedis(t, "")
+	backend := NewRedisBackend(srv.addr())
+	defer backend.Close()
+	ctx := context.Background()
+
+	// two replicas sharing one backend
+	next := &mockTwitchClient{}
+	a := NewClient(next, WithBackend(backend))
+	b := NewClient(next, WithBackend(backend))
+
+	videos, err := a.FetchVideos(ctx, "chan", 1, model.VideoFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	shared, err := b.FetchVideos(ctx, "chan", 1, model.VideoFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next.callCount() != 1 || shared[0].Title != videos[0].Title {
+		t.Errorf("wanted second replica served from the shared cache, got %d calls and %+v", next.callCount(), shared)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if _, ok := srv.data["0:"+DefaultNamespace+"videos?channel=chan&language=&limit=1&period=&sort=&type="]; !ok {
+		t.Errorf("wanted response stored under the namespaced key, got keys %v", srv.data)
+	}
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +15,43 @@ type Config struct {
 	ClientID     string
 	ClientSecret string
 	ChannelID    string
+
+	// AuthURL and HelixURL override the Twitch OAuth and Helix base URLs, e.g. to
+	// point at a stand-in server; empty uses the real Twitch endpoints.
+	AuthURL     string
+	HelixURL    string
+	HTTPTimeout time.Duration
+
+	// CacheTTL, CacheStaleTTL and CacheMaxEntries configure the Twitch response
+	// cache; a zero TTL disables it. CacheBackend is "memory" or "redis".
+	CacheTTL        time.Duration
+	CacheStaleTTL   time.Duration
+	CacheMaxEntries int
+	CacheBackend    string
+	CacheNamespace  string
+	RedisAddr       string
+	RedisPassword   string
+	RedisDB         int
+
+	// SnapshotDB is the path of the bbolt file recording video view counts over
+	// time; empty disables recording.
+	SnapshotDB string
+
+	// PollInterval is how often each channel in ChannelID, a comma-separated
+	// list, is polled for snapshots, unless the channel sets its own as in
+	// "12826:5m".
+	PollInterval time.Duration
+
+	// WatchlistFile is the path of the JSON file holding channels added through
+	// the watchlist API, polled alongside ChannelID.
+	WatchlistFile string
+
+	// RetentionRaw and RetentionHourly are how long snapshots are kept raw and
+	// hourly before being rolled up to hourly and daily, checked every
+	// CompactInterval; zero disables that roll-up.
+	RetentionRaw    time.Duration
+	RetentionHourly time.Duration
+	CompactInterval time.Duration
 }
 
 // LoadEnv loads environment variables given a path
@@ -25,12 +65,106 @@ func LoadEnv(envFile string) Config {
 		ClientID:     getEnv("TWITCH_CLIENT_ID", ""),
 		ClientSecret: getEnv("TWITCH_CLIENT_SECRET", ""),
 		ChannelID:    getEnv("TWITCH_CHANNEL_ID", ""),
+		AuthURL:      getEnv("TWITCH_AUTH_URL", ""),
+		HelixURL:     getEnv("TWITCH_HELIX_URL", ""),
+		HTTPTimeout:  getDurationEnv("HTTP_TIMEOUT", 10*time.Second),
+
+		CacheTTL:        getDurationEnv("CACHE_TTL", time.Minute),
+		CacheStaleTTL:   getDurationEnv("CACHE_STALE_TTL", 5*time.Minute),
+		CacheMaxEntries: getIntEnv("CACHE_MAX_ENTRIES", 1000),
+		CacheBackend:    getEnv("CACHE_BACKEND", "memory"),
+		CacheNamespace:  getEnv("CACHE_NAMESPACE", ""),
+		RedisAddr:       getEnv("REDIS_ADDR", "localhost:6379"),
+		RedisPassword:   getEnv("REDIS_PASSWORD", ""),
+		RedisDB:         getIntEnv("REDIS_DB", 0),
+
+		SnapshotDB:    getEnv("SNAPSHOT_DB", "snapshots.db"),
+		PollInterval:  getIntervalEnv("POLL_INTERVAL", 15*time.Minute),
+		WatchlistFile: getEnv("WATCHLIST_FILE", "watchlist.json"),
+
+		RetentionRaw:    getDurationEnv("RETENTION_RAW", 7*24*time.Hour),
+		RetentionHourly: getDurationEnv("RETENTION_HOURLY", 90*24*time.Hour),
+		CompactInterval: getIntervalEnv("COMPACT_INTERVAL", time.Hour),
 	}
 }
 
+// ChannelIntervals returns the channels listed in ChannelID, separated by
+// commas, each mapped to the poll interval following it after a colon, or zero
+// for PollInterval. An invalid interval is logged and replaced by zero.
+func (c Config) ChannelIntervals() map[string]time.Duration {
+	channels := make(map[string]time.Duration)
+	for _, item := range strings.Split(c.ChannelID, ",") {
+		id, interval, hasInterval := strings.Cut(strings.TrimSpace(item), ":")
+		if id == "" {
+			continue
+		}
+
+		var d time.Duration
+		if hasInterval {
+			var err error
+			if d, err = time.ParseDuration(interval); err != nil || d <= 0 {
+				log.Printf("invalid poll interval %q for channel %s, using %s", interval, id, c.PollInterval)
+				d = 0
+			}
+		}
+		channels[id] = d
+	}
+	return channels
+}
+
+// FirstChannelID returns the first channel listed in ChannelID, without its
+// poll interval, or "" if none is
+func (c Config) FirstChannelID() string {
+	for _, item := range strings.Split(c.ChannelID, ",") {
+		if id, _, _ := strings.Cut(strings.TrimSpace(item), ":"); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
 func getEnv(key, defaultVal string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
 	}
 	return defaultVal
 }
+
+// getDurationEnv parses a duration such as "30s", falling back to defaultVal if unset or invalid
+func getDurationEnv(key string, defaultVal time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultVal
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("invalid %s %q, using %s", key, value, defaultVal)
+		return defaultVal
+	}
+	return d
+}
+
+// getIntervalEnv parses a duration like getDurationEnv, also falling back to
+// defaultVal if it is not positive
+func getIntervalEnv(key string, defaultVal time.Duration) time.Duration {
+	d := getDurationEnv(key, defaultVal)
+	if d <= 0 {
+		log.Printf("invalid %s %s, must be positive, using %s", key, d, defaultVal)
+		return defaultVal
+	}
+	return d
+}
+
+// getIntEnv parses an integer, falling back to defaultVal if unset or invalid
+func getIntEnv(key string, defaultVal int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultVal
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("invalid %s %q, using %d", key, value, defaultVal)
+		return defaultVal
+	}
+	return n
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/service"
+	"fourthfloor/internal/twitch"
+	"fourthfloor/internal/watchlist"
+)
+
+// problem codes, the last segment of a Problem's type
+const (
+	problemInvalidParameter    = "invalid-parameter"
+	problemInvalidBody         = "invalid-body"
+	problemNotFound            = "not-found"
+	problemMethodNotAllowed    = "method-not-allowed"
+	problemNoVideos            = "no-videos"
+	problemNoHistory           = "no-history"
+	problemChannelNotFound     = "channel-not-found"
+	problemNotWatched          = "not-watched"
+	problemAlreadyWatched      = "already-watched"
+	problemRateLimited         = "rate-limited"
+	problemTimeout             = "timeout"
+	problemClientClosed        = "client-closed-request"
+	problemUpstreamError       = "upstream-error"
+	problemUpstreamUnavailable = "upstream-unavailable"
+	problemUpstreamTimeout     = "upstream-timeout"
+	problemInternal            = "internal"
+)
+
+// statusClientClosedRequest is the non-standard status logged for requests the
+// client gave up on before a response was written
+const statusClientClosedRequest = 499
+
+// retryAfterer errors carrying how long the caller should wait before retrying
+type retryAfterer interface {
+	RetryAfter() time.Duration
+}
+
+// writeProblem writes a problem+json error body for the request
+func writeProblem(w http.ResponseWriter, r *http.Request, p model.Problem) {
+	p.Title = http.StatusText(p.Status)
+	p.Instance = r.URL.RequestURI()
+	p.RequestID = RequestIDFromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// writeInvalidParam writes a 400 problem for an invalid query parameter
+func writeInvalidParam(w http.ResponseWriter, r *http.Request, param string) {
+	writeProblem(w, r, model.Problem{
+		Type:   problemType(problemInvalidParameter),
+		Status: http.StatusBadRequest,
+		Detail: "Invalid query parameter '" + param + "'",
+	})
+}
+
+// writeInvalidField writes a 400 problem for an invalid request body field
+func writeInvalidField(w http.ResponseWriter, r *http.Request, field string) {
+	writeProblem(w, r, model.Problem{
+		Type:   problemType(problemInvalidBody),
+		Status: http.StatusBadRequest,
+		Detail: "Invalid field '" + field + "'",
+	})
+}
+
+// writeServiceError writes err as a problem with the HTTP status it maps to.
+// Unexpected errors are logged with the request ID rather than shown, since they
+// may carry internals such as file paths. A request cancelled by its client gets
+// a bare 499, since nobody is left to read a body.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	status, code := classifyError(err)
+	if status == statusClientClosedRequest {
+		w.WriteHeader(status)
+		return
+	}
+
+	p := model.Problem{Type: problemType(code), Status: status, Detail: err.Error()}
+	if status == http.StatusInternalServerError {
+		log.Printf("request %s: %s %s: %v", RequestIDFromContext(r.Context()), r.Method, r.URL.Path, err)
+		p.Detail = "Internal error"
+	}
+
+	var apiErr *twitch.APIError
+	if errors.As(err, &apiErr) {
+		p.UpstreamStatus = apiErr.StatusCode
+	}
+
+	var hint retryAfterer
+	if (status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable) && errors.As(err, &hint) && hint.RetryAfter() > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(hint.RetryAfter().Seconds()))))
+	}
+
+	writeProblem(w, r, p)
+}
+
+// NotFoundHandler writes a 404 problem for requests matching no route
+func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, model.Problem{Type: problemType(problemNotFound), Status: http.StatusNotFound})
+}
+
+// MethodNotAllowedHandler writes a 405 problem for routes matched with the wrong method
+func MethodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, model.Problem{Type: problemType(problemMethodNotAllowed), Status: http.StatusMethodNotAllowed})
+}
+
+// classifyError maps service, watchlist and Twitch errors to an HTTP status and
+// problem code: missing channels, videos, view history or watchlist entries are
+// 404, adding a channel already watched 409, exhausted rate budget 429, a timed
+// out call 504, and Twitch failing or rejecting the request 502, or 503/504 when
+// Twitch itself said so. A call cancelled because the client went away is 499.
+func classifyError(err error) (int, string) {
+	var apiErr *twitch.APIError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest, problemClientClosed
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, problemTimeout
+	case errors.Is(err, service.ErrNoVideos):
+		return http.StatusNotFound, problemNoVideos
+	case errors.Is(err, service.ErrNoHistory):
+		return http.StatusNotFound, problemNoHistory
+	case errors.Is(err, twitch.ErrChannelNotFound):
+		return http.StatusNotFound, problemChannelNotFound
+	case errors.Is(err, watchlist.ErrNotWatched):
+		return http.StatusNotFound, problemNotWatched
+	case errors.Is(err, watchlist.ErrAlreadyWatched):
+		return http.StatusConflict, problemAlreadyWatched
+	case errors.Is(err, twitch.ErrRateLimited):
+		return http.StatusTooManyRequests, problemRateLimited
+	case errors.As(err, &apiErr):
+		switch apiErr.StatusCode {
+		case http.StatusServiceUnavailable:
+			return http.StatusServiceUnavailable, problemUpstreamUnavailable
+		case http.StatusGatewayTimeout:
+			return http.StatusGatewayTimeout, problemUpstreamTimeout
+		}
+		return http.StatusBadGateway, problemUpstreamError
+	case errors.Is(err, twitch.ErrUpstreamUnavailable):
+		return http.StatusBadGateway, problemUpstreamError
+	default:
+		return http.StatusInternalServerError, problemInternal
+	}
+}
+
+// problemType returns the type URI reference for a problem code
+func problemType(code string) string {
+	return "/problems/" + code
+}
@@ -0,0 +1,172 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fourthfloor/internal/handlers"
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/service"
+	"fourthfloor/internal/twitch"
+	"fourthfloor/internal/watchlist"
+
+	"github.com/gorilla/mux"
+)
+
+// ---- Mocks ----
+
+// retryAfterErr error carrying a retry hint, as returned by the Twitch client
+type retryAfterErr struct {
+	error
+	after time.Duration
+}
+
+func (e retryAfterErr) Unwrap() error             { return e.error }
+func (e retryAfterErr) RetryAfter() time.Duration { return e.after }
+
+// decodeProblem decodes a problem+json response body
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) model.Problem {
+	t.Helper()
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected problem+json content type, got %q", ct)
+	}
+
+	var p model.Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	return p
+}
+
+// ---- Tests ----
+
+func TestServiceErrorMapping(t *testing.T) {
+	tests := []struct {
+		name             string
+		err              error
+		expectedCode     int
+		expectedType     string
+		expectedUpstream int
+		expectedRetry    string
+		expectedDetail   string
+	}{
+		{name: "no videos", err: fmt.Errorf("growth: %w", service.ErrNoVideos), expectedCode: http.StatusNotFound, expectedType: "/problems/no-videos", expectedDetail: "growth: no videos found"},
+		{name: "unknown channel", err: &twitch.APIError{StatusCode: 404, Message: "user not found"}, expectedCode: http.StatusNotFound, expectedType: "/problems/channel-not-found", expectedUpstream: 404, expectedDetail: "twitch API returned 404: user not found"},
+		{name: "no history", err: service.ErrNoHistory, expectedCode: http.StatusNotFound, expectedType: "/problems/no-history"},
+		{name: "not watched", err: watchlist.ErrNotWatched, expectedCode: http.StatusNotFound, expectedType: "/problems/not-watched"},
+		{name: "already watched", err: watchlist.ErrAlreadyWatched, expectedCode: http.StatusConflict, expectedType: "/problems/already-watched"},
+		{name: "rate limited", err: retryAfterErr{twitch.ErrRateLimited, 1500 * time.Millisecond}, expectedCode: http.StatusTooManyRequests, expectedType: "/problems/rate-limited", expectedRetry: "2"},
+		{name: "rate limited without hint", err: twitch.ErrRateLimited, expectedCode: http.StatusTooManyRequests, expectedType: "/problems/rate-limited"},
+		{name: "twitch unavailable", err: &twitch.APIError{StatusCode: 503}, expectedCode: http.StatusServiceUnavailable, expectedType: "/problems/upstream-unavailable", expectedUpstream: 503},
+		{name: "twitch gateway timeout", err: &twitch.APIError{StatusCode: 504}, expectedCode: http.StatusGatewayTimeout, expectedType: "/problems/upstream-timeout", expectedUpstream: 504},
+		{name: "twitch server error", err: &twitch.APIError{StatusCode: 500}, expectedCode: http.StatusBadGateway, expectedType: "/problems/upstream-error", expectedUpstream: 500},
+		{name: "twitch rejected request", err: &twitch.APIError{StatusCode: 400, Message: "Malformed query params"}, expectedCode: http.StatusBadGateway, expectedType: "/problems/upstream-error", expectedUpstream: 400},
+		{name: "twitch unreachable", err: fmt.Errorf("%w: connection refused", twitch.ErrUpstreamUnavailable), expectedCode: http.StatusBadGateway, expectedType: "/problems/upstream-error"},
+		{name: "deadline", err: context.DeadlineExceeded, expectedCode: http.StatusGatewayTimeout, expectedType: "/problems/timeout"},
+		{name: "unexpected", err: errors.New("boom"), expectedCode: http.StatusInternalServerError, expectedType: "/problems/internal", expectedDetail: "Internal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &handlers.VideoHandler{Service: &mockVideoService{Err: tt.err}}
+
+			req := httptest.NewRequest("GET", "/streamers/123/videos?n=5", nil)
+			req = mux.SetURLVars(req, map[string]string{"channel_id": "123"})
+			rec := httptest.NewRecorder()
+			handlers.RequestID(http.HandlerFunc(handler.GetStreamerVideosHandler)).ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.expectedRetry {
+				t.Errorf("expected Retry-After %q, got %q", tt.expectedRetry, got)
+			}
+
+			problem := decodeProblem(t, rec)
+			if problem.Type != tt.expectedType || problem.Status != tt.expectedCode || problem.UpstreamStatus != tt.expectedUpstream {
+				t.Errorf("expected type %s status %d upstream %d, got %+v", tt.expectedType, tt.expectedCode, tt.expectedUpstream, problem)
+			}
+			if tt.expectedDetail != "" && problem.Detail != tt.expectedDetail {
+				t.Errorf("expected detail %q, got %q", tt.expectedDetail, problem.Detail)
+			}
+			if problem.Title != http.StatusText(tt.expectedCode) || problem.Instance != "/streamers/123/videos?n=5" {
+				t.Errorf("expected title and instance for the request, got %+v", problem)
+			}
+			if problem.RequestID == "" || problem.RequestID != rec.Header().Get("X-Request-ID") {
+				t.Errorf("expected request ID %q in body, got %q", rec.Header().Get("X-Request-ID"), problem.RequestID)
+			}
+		})
+	}
+}
+
+func TestServiceErrorClientClosed(t *testing.T) {
+	handler := &handlers.VideoHandler{Service: &mockVideoService{Err: fmt.Errorf("fetch videos: %w", context.Canceled)}}
+
+	req := httptest.NewRequest("GET", "/streamers/123/videos?n=5", nil)
+	req = mux.SetURLVars(req, map[string]string{"channel_id": "123"})
+	rec := httptest.NewRecorder()
+	handler.GetStreamerVideosHandler(rec, req)
+
+	if rec.Code != 499 || rec.Body.Len() != 0 {
+		t.Errorf("expected a bare 499 for a cancelled request, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestInvalidParamProblem(t *testing.T) {
+	handler := &handlers.VideoHandler{Service: &mockVideoService{}}
+
+	req := httptest.NewRequest("GET", "/streamers/123/videos?n=abc", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	req = mux.SetURLVars(req, map[string]string{"channel_id": "123"})
+	rec := httptest.NewRecorder()
+	handlers.RequestID(http.HandlerFunc(handler.GetStreamerVideosHandler)).ServeHTTP(rec, req)
+
+	problem := decodeProblem(t, rec)
+	expected := model.Problem{
+		Type:      "/problems/invalid-parameter",
+		Title:     "Bad Request",
+		Status:    http.StatusBadRequest,
+		Detail:    "Invalid query parameter 'n'",
+		Instance:  "/streamers/123/videos?n=abc",
+		RequestID: "req-42",
+	}
+	if problem != expected {
+		t.Errorf("expected %+v, got %+v", expected, problem)
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "req-42" {
+		t.Errorf("expected caller's request ID echoed, got %q", got)
+	}
+}
+
+func TestNotFoundProblem(t *testing.T) {
+	r := mux.NewRouter()
+	r.HandleFunc("/compare", (&handlers.VideoHandler{Service: &mockVideoService{}}).CompareChannelsHandler).Methods("GET")
+	r.NotFoundHandler = http.HandlerFunc(handlers.NotFoundHandler)
+	r.MethodNotAllowedHandler = http.HandlerFunc(handlers.MethodNotAllowedHandler)
+
+	tests := []struct {
+		method       string
+		path         string
+		expectedCode int
+		expectedType string
+	}{
+		{method: "GET", path: "/nowhere", expectedCode: http.StatusNotFound, expectedType: "/problems/not-found"},
+		{method: "POST", path: "/compare", expectedCode: http.StatusMethodNotAllowed, expectedType: "/problems/method-not-allowed"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handlers.RequestID(r).ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if problem := decodeProblem(t, rec); rec.Code != tt.expectedCode || problem.Type != tt.expectedType {
+			t.Errorf("%s %s: expected %d %s, got %d %+v", tt.method, tt.path, tt.expectedCode, tt.expectedType, rec.Code, problem)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// HistoryHandler serves the view history recorded for videos
+type HistoryHandler struct {
+	Service service.HistoryServiceInterface
+
+	// Timeout bounds the service call of each request, defaults to 60s
+	Timeout time.Duration
+}
+
+// GetVideoHistoryHandler handler to return the recorded view history of a video
+// given its ID (path parameter), downsampled by the resolution query parameter
+// (raw, hour or day). Optional since and until (RFC3339) restrict the returned
+// points, and first_days sets the days after publishing views are reported for.
+func (h *HistoryHandler) GetVideoHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	videoID := mux.Vars(r)["video_id"]
+
+	query, badParam := parseHistoryQuery(r.URL.Query())
+	if badParam != "" {
+		writeInvalidParam(w, r, badParam)
+		return
+	}
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	history, err := h.Service.GetVideoHistory(ctx, videoID, query)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, history)
+}
+
+// parseHistoryQuery reads the history query parameters, returning the name of
+// the first invalid parameter if any
+func parseHistoryQuery(values url.Values) (model.HistoryQuery, string) {
+	query := model.HistoryQuery{Resolution: model.HistoryResolution(values.Get("resolution"))}
+	if !query.Resolution.Valid() {
+		return query, "resolution"
+	}
+
+	var ok bool
+	if query.Since, ok = parseTimeParam(values, "since"); !ok {
+		return query, "since"
+	}
+	if query.Until, ok = parseTimeParam(values, "until"); !ok {
+		return query, "until"
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && !query.Since.Before(query.Until) {
+		return query, "since"
+	}
+
+	if query.FirstDays, ok = parseCountParam(values, "first_days"); !ok || values.Has("first_days") && query.FirstDays == 0 {
+		return query, "first_days"
+	}
+
+	return query, ""
+}
@@ -0,0 +1,90 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fourthfloor/internal/handlers"
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// ---- Mocks ----
+
+// mockHistoryService implements HistoryServiceInterface, recording the query it was given
+type mockHistoryService struct {
+	err   error
+	query model.HistoryQuery
+}
+
+func (m *mockHistoryService) GetVideoHistory(ctx context.Context, videoID string, query model.HistoryQuery) (model.VideoHistoryResponse, error) {
+	m.query = query
+	return model.VideoHistoryResponse{VideoID: videoID, Resolution: query.Resolution}, m.err
+}
+
+// ---- Tests ----
+
+func TestGetVideoHistoryHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		serviceErr    error
+		expectedCode  int
+		expectedType  string
+		expectedQuery model.HistoryQuery
+	}{
+		{name: "defaults", url: "/videos/v1/history", expectedCode: http.StatusOK},
+		{
+			name:         "all parameters",
+			url:          "/videos/v1/history?resolution=hour&since=2025-09-01T00:00:00Z&until=2025-09-08T00:00:00Z&first_days=3",
+			expectedCode: http.StatusOK,
+			expectedQuery: model.HistoryQuery{
+				Resolution: model.HistoryResolutionHour,
+				Since:      time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+				Until:      time.Date(2025, 9, 8, 0, 0, 0, 0, time.UTC),
+				FirstDays:  3,
+			},
+		},
+		{name: "invalid resolution", url: "/videos/v1/history?resolution=minute", expectedCode: http.StatusBadRequest, expectedType: "/problems/invalid-parameter"},
+		{name: "invalid since", url: "/videos/v1/history?since=yesterday", expectedCode: http.StatusBadRequest, expectedType: "/problems/invalid-parameter"},
+		{name: "since after until", url: "/videos/v1/history?since=2025-09-08T00:00:00Z&until=2025-09-01T00:00:00Z", expectedCode: http.StatusBadRequest, expectedType: "/problems/invalid-parameter"},
+		{name: "zero first days", url: "/videos/v1/history?first_days=0", expectedCode: http.StatusBadRequest, expectedType: "/problems/invalid-parameter"},
+		{name: "no history", url: "/videos/v1/history", serviceErr: service.ErrNoHistory, expectedCode: http.StatusNotFound, expectedType: "/problems/no-history"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mockHistoryService{err: tt.serviceErr}
+			h := &handlers.HistoryHandler{Service: svc}
+			r := mux.NewRouter()
+			r.HandleFunc("/videos/{video_id}/history", h.GetVideoHistoryHandler).Methods("GET")
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest("GET", tt.url, nil))
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedCode, rec.Code, rec.Body)
+			}
+			if tt.expectedType != "" {
+				if p := decodeProblem(t, rec); p.Type != tt.expectedType {
+					t.Errorf("expected type %s, got %s", tt.expectedType, p.Type)
+				}
+				return
+			}
+
+			var resp model.VideoHistoryResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.VideoID != "v1" {
+				t.Errorf("unexpected response %s", rec.Body)
+			}
+			if svc.query != tt.expectedQuery {
+				t.Errorf("expected query %+v, got %+v", tt.expectedQuery, svc.query)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"fourthfloor/internal/model"
+)
+
+// StatsReporter reports the size of the snapshot store
+type StatsReporter interface {
+	Stats(ctx context.Context) (model.StorageStats, error)
+}
+
+// StorageHandler serves metrics about the snapshot store
+type StorageHandler struct {
+	Store StatsReporter
+}
+
+// GetStorageStatsHandler handler to return the number of videos and snapshots
+// stored, the size of the store and the outcome of the last compaction.
+func (h *StorageHandler) GetStorageStatsHandler(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.Store.Stats(r.Context())
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, stats)
+}
@@ -0,0 +1,45 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fourthfloor/internal/handlers"
+	"fourthfloor/internal/model"
+)
+
+// ---- Mocks ----
+
+// mockStatsReporter returns fixed stats or an error
+type mockStatsReporter struct {
+	stats model.StorageStats
+	err   error
+}
+
+func (m mockStatsReporter) Stats(ctx context.Context) (model.StorageStats, error) {
+	return m.stats, m.err
+}
+
+// ---- Tests ----
+
+func TestGetStorageStatsHandler(t *testing.T) {
+	h := &handlers.StorageHandler{Store: mockStatsReporter{stats: model.StorageStats{Videos: 2, Snapshots: 10, FileSizeBytes: 32768}}}
+	rec := httptest.NewRecorder()
+	h.GetStorageStatsHandler(rec, httptest.NewRequest("GET", "/storage/stats", nil))
+
+	var stats model.StorageStats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil || rec.Code != http.StatusOK || stats.Snapshots != 10 || stats.FileSizeBytes != 32768 {
+		t.Errorf("unexpected response %d %+v", rec.Code, stats)
+	}
+
+	h = &handlers.StorageHandler{Store: mockStatsReporter{err: errors.New("database not open")}}
+	rec = httptest.NewRecorder()
+	h.GetStorageStatsHandler(rec, httptest.NewRequest("GET", "/storage/stats", nil))
+	if p := decodeProblem(t, rec); p.Status != http.StatusInternalServerError || p.Type != "/problems/internal" {
+		t.Errorf("expected internal problem, got %+v", p)
+	}
+}
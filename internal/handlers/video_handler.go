@@ -1,45 +1,302 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
+	"fourthfloor/internal/model"
 	"fourthfloor/internal/service"
 
 	"github.com/gorilla/mux"
 )
 
+const (
+	// maxCompareChannels limits the number of channels in a single comparison
+	maxCompareChannels = 50
+
+	// maxVideos limits n, the number of videos a single request may fetch
+	maxVideos = 1000
+
+	// defaultRequestTimeout bounds the service call of a single request
+	defaultRequestTimeout = 60 * time.Second
+)
+
 type VideoHandler struct {
 	Service service.VideoServiceInterface
+
+	// Timeout bounds the service call of each request, defaults to 60s. The
+	// call is also cancelled if the client disconnects.
+	Timeout time.Duration
+}
+
+// requestContext returns the request's context bounded by the handler timeout
+func (h *VideoHandler) requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	return context.WithTimeout(r.Context(), timeout)
 }
 
 // GetStreamerVideosHandler handler to return n (query parameter) videos for a single
-// streamer given their channel ID (path parameter)
+// streamer given their channel ID (path parameter). Optional type, period, sort and
+// language query parameters filter the Twitch videos fetched. Optional
+// since and until (RFC3339) restrict stats to videos created in that window, in
+// which case n becomes an optional upper cap. include_videos adds per-video metrics
+// and top/bottom add ranked lists of the best and worst videos by rank_by.
 func (h *VideoHandler) GetStreamerVideosHandler(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	channelID := params["channel_id"]
-	nStr := r.URL.Query().Get("n")
 
-	n, err := strconv.Atoi(nStr)
-	if err != nil || n <= 0 {
-		http.Error(w, "Invalid query parameter 'n'", http.StatusBadRequest)
+	query, badParam := parseStatsQuery(r.URL.Query())
+	if badParam != "" {
+		writeInvalidParam(w, r, badParam)
+		return
+	}
+
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	stats, err := h.Service.GetVideoStats(ctx, channelID, query)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, stats)
+}
+
+// GetStreamerVideosByLoginHandler handler to return video stats for a single
+// streamer given their login name (path parameter), resolving it to a channel ID.
+// Accepts the same query parameters as GetStreamerVideosHandler and includes the
+// user profile in the response.
+func (h *VideoHandler) GetStreamerVideosByLoginHandler(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	login := params["login"]
+
+	query, badParam := parseStatsQuery(r.URL.Query())
+	if badParam != "" {
+		writeInvalidParam(w, r, badParam)
+		return
+	}
+
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	stats, err := h.Service.GetVideoStatsByLogin(ctx, login, query)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, stats)
+}
+
+// GetStreamerVideoGrowthHandler handler to compare stats for a streamer's latest
+// period with the preceding equivalent period. Accepts the same query parameters
+// as GetStreamerVideosHandler; a date window must include since.
+func (h *VideoHandler) GetStreamerVideoGrowthHandler(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	channelID := params["channel_id"]
+
+	query, badParam := parseStatsQuery(r.URL.Query())
+	if badParam == "" && query.Windowed() && query.Since.IsZero() {
+		badParam = "since"
+	}
+	if badParam != "" {
+		writeInvalidParam(w, r, badParam)
+		return
+	}
+
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	growth, err := h.Service.GetVideoGrowth(ctx, channelID, query)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, growth)
+}
+
+// CompareChannelsHandler handler to compare stats across the comma separated
+// channel IDs in the channels query parameter, ranked by the metric query
+// parameter. Accepts the same stats query parameters as GetStreamerVideosHandler.
+func (h *VideoHandler) CompareChannelsHandler(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+
+	channelIDs := parseChannelList(values.Get("channels"))
+	if len(channelIDs) == 0 || len(channelIDs) > maxCompareChannels {
+		writeInvalidParam(w, r, "channels")
+		return
+	}
+
+	query, badParam := parseStatsQuery(values)
+	if badParam == "" && values.Get("metric") != "" && !service.IsStatsMetric(values.Get("metric")) {
+		badParam = "metric"
+	}
+	if badParam != "" {
+		writeInvalidParam(w, r, badParam)
 		return
 	}
 
-	stats, err := h.Service.GetVideoStats(channelID, n)
+	ctx, cancel := h.requestContext(r)
+	defer cancel()
+
+	comparison, err := h.Service.CompareChannels(ctx, channelIDs, query, values.Get("metric"))
 	if err != nil {
-		// map service errors to HTTP codes
-		if err.Error() == "no videos found" {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, "Failed to get video stats: "+err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, comparison)
+}
+
+// parseChannelList splits a comma separated channel list, dropping blanks and
+// duplicates while keeping order
+func parseChannelList(list string) []string {
+	var channels []string
+	seen := make(map[string]bool)
+
+	for _, c := range strings.Split(list, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" || seen[c] {
+			continue
 		}
+		seen[c] = true
+		channels = append(channels, c)
+	}
+
+	return channels
+}
+
+// writeJSON writes v as a 200 JSON response body
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	writeJSONStatus(w, r, http.StatusOK, v)
+}
+
+// writeJSONStatus writes v as a JSON response body with status. v is encoded
+// before anything is written, so that a failure can still be reported as a problem.
+func writeJSONStatus(w http.ResponseWriter, r *http.Request, status int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeServiceError(w, r, fmt.Errorf("encode response: %w", err))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(stats); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	w.WriteHeader(status)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// parseStatsQuery reads the stats query parameters, returning the name of the
+// first invalid parameter if any
+func parseStatsQuery(values url.Values) (model.StatsQuery, string) {
+	var query model.StatsQuery
+	var badParam string
+
+	if query.Filter, badParam = parseVideoFilter(values); badParam != "" {
+		return query, badParam
+	}
+
+	var ok bool
+	if query.Since, ok = parseTimeParam(values, "since"); !ok {
+		return query, "since"
+	}
+	if query.Until, ok = parseTimeParam(values, "until"); !ok {
+		return query, "until"
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && !query.Since.Before(query.Until) {
+		return query, "since"
+	}
+
+	if query.Top, ok = parseCountParam(values, "top"); !ok {
+		return query, "top"
+	}
+	if query.Bottom, ok = parseCountParam(values, "bottom"); !ok {
+		return query, "bottom"
 	}
+	if query.FirstDays, ok = parseCountParam(values, "first_days"); !ok {
+		return query, "first_days"
+	}
+
+	query.RankBy = model.RankMetric(values.Get("rank_by"))
+	if !query.RankBy.Valid() {
+		return query, "rank_by"
+	}
+
+	if v := values.Get("include_videos"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, "include_videos"
+		}
+		query.IncludeVideos = include
+	}
+
+	// n is required unless a date window bounds the request
+	nStr := values.Get("n")
+	if nStr == "" && query.Windowed() {
+		return query, ""
+	}
+
+	n, err := strconv.Atoi(nStr)
+	if err != nil || n <= 0 || n > maxVideos {
+		return query, "n"
+	}
+	query.Limit = n
+
+	return query, ""
+}
+
+// parseTimeParam reads an optional RFC3339 query parameter, returning the zero
+// time if it is absent and false if it is malformed
+func parseTimeParam(values url.Values, name string) (time.Time, bool) {
+	v := values.Get(name)
+	if v == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, err == nil
+}
+
+// parseCountParam reads an optional non-negative integer query parameter,
+// returning zero if it is absent and false if it is malformed
+func parseCountParam(values url.Values, name string) (int, bool) {
+	v := values.Get(name)
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	return n, err == nil && n >= 0
+}
+
+// parseVideoFilter reads the video filter query parameters, returning the name
+// of the first invalid parameter if any
+func parseVideoFilter(query url.Values) (model.VideoFilter, string) {
+	filter := model.VideoFilter{
+		Type:     model.VideoType(query.Get("type")),
+		Period:   model.VideoPeriod(query.Get("period")),
+		Sort:     model.VideoSort(query.Get("sort")),
+		Language: query.Get("language"),
+	}
+
+	switch {
+	case !filter.Type.Valid():
+		return filter, "type"
+	case !filter.Period.Valid():
+		return filter, "period"
+	case !filter.Sort.Valid():
+		return filter, "sort"
+	case !model.ValidLanguage(filter.Language):
+		return filter, "language"
+	}
+
+	return filter, ""
 }
@@ -34,7 +34,7 @@ func TestVideoHandler_Integration(t *testing.T) {
 	router := mux.NewRouter()
 	router.HandleFunc("/streamers/{channel_id}/videos", handler.GetStreamerVideosHandler).Methods("GET")
 
-	req := httptest.NewRequest("GET", "/streamers/"+cfg.ChannelID+"/videos?n=2", nil)
+	req := httptest.NewRequest("GET", "/streamers/"+cfg.FirstChannelID()+"/videos?n=2", nil)
 	rec := httptest.NewRecorder()
 
 	router.ServeHTTP(rec, req)
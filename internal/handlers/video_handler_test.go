@@ -1,14 +1,20 @@
 package handlers_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fourthfloor/internal/handlers"
 	"fourthfloor/internal/model"
+	"fourthfloor/internal/service"
+	"fourthfloor/internal/twitch"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -17,14 +23,43 @@ import (
 
 // mockVideoService implements VideoServiceInterface for testing.
 type mockVideoService struct {
-	Response model.VideoStatsResponse
-	Err      error
+	Response   model.VideoStatsResponse
+	Growth     model.VideoGrowthResponse
+	Comparison model.ComparisonResponse
+	Err        error
+
+	Ctx      context.Context
+	CtxErr   error // ctx.Err() when the service was called
+	Query    model.StatsQuery
+	Channels []string
+	Metric   string
+}
+
+func (m *mockVideoService) GetVideoStats(ctx context.Context, channelID string, query model.StatsQuery) (model.VideoStatsResponse, error) {
+	m.Ctx = ctx
+	m.CtxErr = ctx.Err()
+	m.Query = query
+	return m.Response, m.Err
 }
 
-func (m *mockVideoService) GetVideoStats(channelID string, limit int) (model.VideoStatsResponse, error) {
+func (m *mockVideoService) GetVideoStatsByLogin(ctx context.Context, login string, query model.StatsQuery) (model.VideoStatsResponse, error) {
+	m.Query = query
+	m.Channels = []string{login}
 	return m.Response, m.Err
 }
 
+func (m *mockVideoService) GetVideoGrowth(ctx context.Context, channelID string, query model.StatsQuery) (model.VideoGrowthResponse, error) {
+	m.Query = query
+	return m.Growth, m.Err
+}
+
+func (m *mockVideoService) CompareChannels(ctx context.Context, channelIDs []string, query model.StatsQuery, metric string) (model.ComparisonResponse, error) {
+	m.Query = query
+	m.Channels = channelIDs
+	m.Metric = metric
+	return m.Comparison, m.Err
+}
+
 // ---- Tests ----
 
 func TestGetStreamerVideosHandler(t *testing.T) {
@@ -55,11 +90,18 @@ func TestGetStreamerVideosHandler(t *testing.T) {
 			expectedCode:   http.StatusBadRequest,
 			expectedInBody: "Invalid query parameter 'n'",
 		},
+		{
+			name:           "n too large",
+			channelID:      "123",
+			queryN:         "9223372036854775807",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'n'",
+		},
 		{
 			name:           "no videos found",
 			channelID:      "123",
 			queryN:         "5",
-			serviceErr:     errors.New("no videos found"),
+			serviceErr:     service.ErrNoVideos,
 			expectedCode:   http.StatusNotFound,
 			expectedInBody: "no videos found",
 		},
@@ -69,7 +111,15 @@ func TestGetStreamerVideosHandler(t *testing.T) {
 			queryN:         "5",
 			serviceErr:     errors.New("some failure"),
 			expectedCode:   http.StatusInternalServerError,
-			expectedInBody: "Failed to get video stats: some failure",
+			expectedInBody: "Internal error",
+		},
+		{
+			name:           "unencodable response",
+			channelID:      "123",
+			queryN:         "5",
+			serviceResp:    model.VideoStatsResponse{AverageViews: math.NaN()},
+			expectedCode:   http.StatusInternalServerError,
+			expectedInBody: `"type":"/problems/internal"`,
 		},
 	}
 
@@ -107,3 +157,435 @@ func TestGetStreamerVideosHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestGetStreamerVideosHandlerFilters(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedCode   int
+		expectedInBody string
+		expectedFilter model.VideoFilter
+	}{
+		{
+			name:         "no filters",
+			query:        "n=5",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "all filters",
+			query:        "n=5&type=archive&period=week&sort=views&language=en",
+			expectedCode: http.StatusOK,
+			expectedFilter: model.VideoFilter{
+				Type:     model.VideoTypeArchive,
+				Period:   model.VideoPeriodWeek,
+				Sort:     model.VideoSortViews,
+				Language: "en",
+			},
+		},
+		{
+			name:           "invalid type",
+			query:          "n=5&type=clip",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'type'",
+		},
+		{
+			name:           "invalid period",
+			query:          "n=5&period=year",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'period'",
+		},
+		{
+			name:           "invalid sort",
+			query:          "n=5&sort=random",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'sort'",
+		},
+		{
+			name:           "invalid language",
+			query:          "n=5&language=english",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'language'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockVideoService{}
+			handler := &handlers.VideoHandler{Service: mockSvc}
+
+			req := httptest.NewRequest("GET", "/streamers/123/videos?"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"channel_id": "123"})
+
+			rec := httptest.NewRecorder()
+			handler.GetStreamerVideosHandler(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedInBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.expectedInBody, rec.Body.String())
+			}
+			if rec.Code == http.StatusOK && mockSvc.Query.Filter != tt.expectedFilter {
+				t.Errorf("expected filter %+v, got %+v", tt.expectedFilter, mockSvc.Query.Filter)
+			}
+		})
+	}
+}
+
+func TestGetStreamerVideosHandlerWindow(t *testing.T) {
+	since := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedCode   int
+		expectedInBody string
+		expectedQuery  model.StatsQuery
+	}{
+		{
+			name:          "window without n",
+			query:         "since=2025-09-01T00:00:00Z&until=2025-10-01T00:00:00Z",
+			expectedCode:  http.StatusOK,
+			expectedQuery: model.StatsQuery{Since: since, Until: until},
+		},
+		{
+			name:          "window capped by n",
+			query:         "since=2025-09-01T00:00:00Z&n=50",
+			expectedCode:  http.StatusOK,
+			expectedQuery: model.StatsQuery{Limit: 50, Since: since},
+		},
+		{
+			name:           "missing n without window",
+			query:          "",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'n'",
+		},
+		{
+			name:           "malformed since",
+			query:          "since=2025-09-01",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'since'",
+		},
+		{
+			name:           "malformed until",
+			query:          "until=yesterday",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'until'",
+		},
+		{
+			name:          "include videos",
+			query:         "n=5&include_videos=true",
+			expectedCode:  http.StatusOK,
+			expectedQuery: model.StatsQuery{Limit: 5, IncludeVideos: true},
+		},
+		{
+			name:          "top and bottom",
+			query:         "n=5&top=3&bottom=2&rank_by=views_per_minute",
+			expectedCode:  http.StatusOK,
+			expectedQuery: model.StatsQuery{Limit: 5, Top: 3, Bottom: 2, RankBy: model.RankByViewsPerMinute},
+		},
+		{
+			name:           "invalid top",
+			query:          "n=5&top=-1",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'top'",
+		},
+		{
+			name:          "first days",
+			query:         "n=5&first_days=30",
+			expectedCode:  http.StatusOK,
+			expectedQuery: model.StatsQuery{Limit: 5, FirstDays: 30},
+		},
+		{
+			name:           "invalid first days",
+			query:          "n=5&first_days=week",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'first_days'",
+		},
+		{
+			name:           "invalid rank metric",
+			query:          "n=5&top=3&rank_by=likes",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'rank_by'",
+		},
+		{
+			name:           "invalid include videos",
+			query:          "n=5&include_videos=maybe",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'include_videos'",
+		},
+		{
+			name:           "since after until",
+			query:          "since=2025-10-01T00:00:00Z&until=2025-09-01T00:00:00Z",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'since'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockVideoService{}
+			handler := &handlers.VideoHandler{Service: mockSvc}
+
+			req := httptest.NewRequest("GET", "/streamers/123/videos?"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"channel_id": "123"})
+
+			rec := httptest.NewRecorder()
+			handler.GetStreamerVideosHandler(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedInBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.expectedInBody, rec.Body.String())
+			}
+			if rec.Code == http.StatusOK && mockSvc.Query != tt.expectedQuery {
+				t.Errorf("expected query %+v, got %+v", tt.expectedQuery, mockSvc.Query)
+			}
+		})
+	}
+}
+
+func TestGetStreamerVideoGrowthHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		expectedCode   int
+		expectedInBody string
+	}{
+		{
+			name:           "last n videos",
+			query:          "n=5",
+			expectedCode:   http.StatusOK,
+			expectedInBody: `"total_views":{"current":200,"previous":100,"absolute":100,"percent":100}`,
+		},
+		{
+			name:         "date window",
+			query:        "since=2025-09-01T00:00:00Z",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:           "window without since",
+			query:          "until=2025-09-01T00:00:00Z",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'since'",
+		},
+		{
+			name:           "invalid n",
+			query:          "n=0",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'n'",
+		},
+		{
+			name:           "no videos found",
+			query:          "n=5",
+			serviceErr:     service.ErrNoVideos,
+			expectedCode:   http.StatusNotFound,
+			expectedInBody: "no videos found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pct := 100.0
+			mockSvc := &mockVideoService{
+				Growth: model.VideoGrowthResponse{
+					Current:  model.VideoStatsResponse{TotalViews: 200},
+					Previous: model.VideoStatsResponse{TotalViews: 100},
+					Deltas: map[string]model.Delta{
+						"total_views": {Current: 200, Previous: 100, Absolute: 100, Percent: &pct},
+					},
+				},
+				Err: tt.serviceErr,
+			}
+			handler := &handlers.VideoHandler{Service: mockSvc}
+
+			req := httptest.NewRequest("GET", "/streamers/123/videos/growth?"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"channel_id": "123"})
+
+			rec := httptest.NewRecorder()
+			handler.GetStreamerVideoGrowthHandler(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedInBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.expectedInBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+// manyChannels returns a comma separated list of n distinct channel IDs
+func manyChannels(n int) string {
+	channels := make([]string, n)
+	for i := range channels {
+		channels[i] = strconv.Itoa(i)
+	}
+	return strings.Join(channels, ",")
+}
+
+func TestCompareChannelsHandler(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		expectedCode     int
+		expectedInBody   string
+		expectedChannels []string
+		expectedMetric   string
+	}{
+		{
+			name:             "channels and metric",
+			query:            "channels=a,b,c&n=20&metric=views_distribution.median",
+			expectedCode:     http.StatusOK,
+			expectedInBody:   `"ranking":[{"rank":1,"channel_id":"b","value":200}`,
+			expectedChannels: []string{"a", "b", "c"},
+			expectedMetric:   "views_distribution.median",
+		},
+		{
+			name:             "blank and duplicate channels dropped",
+			query:            "channels=a,,b,%20a&n=20",
+			expectedCode:     http.StatusOK,
+			expectedChannels: []string{"a", "b"},
+		},
+		{
+			name:           "missing channels",
+			query:          "n=20",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'channels'",
+		},
+		{
+			name:           "too many channels",
+			query:          "n=20&channels=" + manyChannels(51),
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'channels'",
+		},
+		{
+			name:           "unknown metric",
+			query:          "channels=a,b&n=20&metric=likes",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'metric'",
+		},
+		{
+			name:           "invalid n",
+			query:          "channels=a,b",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'n'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockVideoService{
+				Comparison: model.ComparisonResponse{
+					Metric:  "views_distribution.median",
+					Ranking: []model.RankingEntry{{Rank: 1, ChannelID: "b", Value: 200}},
+				},
+			}
+			handler := &handlers.VideoHandler{Service: mockSvc}
+
+			req := httptest.NewRequest("GET", "/compare?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			handler.CompareChannelsHandler(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedInBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.expectedInBody, rec.Body.String())
+			}
+			if tt.expectedChannels != nil && strings.Join(mockSvc.Channels, ",") != strings.Join(tt.expectedChannels, ",") {
+				t.Errorf("expected channels %v, got %v", tt.expectedChannels, mockSvc.Channels)
+			}
+			if mockSvc.Metric != tt.expectedMetric {
+				t.Errorf("expected metric %q, got %q", tt.expectedMetric, mockSvc.Metric)
+			}
+		})
+	}
+}
+
+func TestGetStreamerVideosByLoginHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		expectedCode   int
+		expectedInBody string
+	}{
+		{
+			name:           "successful case",
+			query:          "n=5",
+			expectedCode:   http.StatusOK,
+			expectedInBody: `"user":{"id":"141981764","login":"twitchdev"`,
+		},
+		{
+			name:           "invalid n query",
+			query:          "n=abc",
+			expectedCode:   http.StatusBadRequest,
+			expectedInBody: "Invalid query parameter 'n'",
+		},
+		{
+			name:           "unknown login",
+			query:          "n=5",
+			serviceErr:     twitch.ErrChannelNotFound,
+			expectedCode:   http.StatusNotFound,
+			expectedInBody: "channel not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockVideoService{
+				Response: model.VideoStatsResponse{
+					TotalViews: 100,
+					User:       &model.User{ID: "141981764", Login: "twitchdev"},
+				},
+				Err: tt.serviceErr,
+			}
+			handler := &handlers.VideoHandler{Service: mockSvc}
+
+			req := httptest.NewRequest("GET", "/streamers/by-login/twitchdev/videos?"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"login": "twitchdev"})
+
+			rec := httptest.NewRecorder()
+			handler.GetStreamerVideosByLoginHandler(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Errorf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedInBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.expectedInBody, rec.Body.String())
+			}
+			if rec.Code == http.StatusOK && mockSvc.Channels[0] != "twitchdev" {
+				t.Errorf("expected login twitchdev passed to service, got %v", mockSvc.Channels)
+			}
+		})
+	}
+}
+
+func TestGetStreamerVideosHandlerContext(t *testing.T) {
+	mockSvc := &mockVideoService{}
+	handler := &handlers.VideoHandler{Service: mockSvc, Timeout: time.Minute}
+
+	req := httptest.NewRequest("GET", "/streamers/123/videos?n=5", nil)
+	req = mux.SetURLVars(req, map[string]string{"channel_id": "123"})
+
+	start := time.Now()
+	handler.GetStreamerVideosHandler(httptest.NewRecorder(), req)
+
+	deadline, ok := mockSvc.Ctx.Deadline()
+	if !ok || deadline.Before(start.Add(time.Minute)) || deadline.After(time.Now().Add(time.Minute)) {
+		t.Errorf("expected service context with a 1m deadline, got %v (%v)", deadline, ok)
+	}
+
+	// a disconnected client's request context is passed on
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	handler.GetStreamerVideosHandler(httptest.NewRecorder(), req.WithContext(ctx))
+
+	if !errors.Is(mockSvc.CtxErr, context.Canceled) {
+		t.Errorf("expected service context cancelled with the request, got %v", mockSvc.CtxErr)
+	}
+}
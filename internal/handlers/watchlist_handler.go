@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/watchlist"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// maxWatchlistBody caps the size of a watchlist request body
+	maxWatchlistBody = 64 << 10
+
+	// maxChannelIDLength bounds a channel id accepted onto the watchlist
+	maxChannelIDLength = 32
+)
+
+// UserResolver resolves a login name to its Twitch user
+type UserResolver interface {
+	FetchUserByLogin(ctx context.Context, login string) (model.User, error)
+}
+
+// WatchlistHandler serves the watchlist of channels polled in the background
+type WatchlistHandler struct {
+	Watchlist *watchlist.Store
+
+	// Users resolves channels added by login
+	Users UserResolver
+
+	// Timeout bounds resolving a login, defaults to 60s
+	Timeout time.Duration
+}
+
+// ListWatchlistHandler handler to return every channel on the watchlist,
+// optionally only those carrying the tag query parameter.
+func (h *WatchlistHandler) ListWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	tag := watchlist.NormalizeTag(r.URL.Query().Get("tag"))
+
+	channels := []model.WatchlistEntry{}
+	for _, e := range h.Watchlist.List() {
+		if tag == "" || slices.Contains(e.Tags, tag) {
+			channels = append(channels, e)
+		}
+	}
+
+	writeJSON(w, r, model.WatchlistResponse{Channels: channels})
+}
+
+// AddWatchlistHandler handler to add a channel to the watchlist by channel_id
+// or login, responding 201 with the stored entry.
+func (h *WatchlistHandler) AddWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	var req model.WatchlistRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	switch {
+	case (req.ChannelID == "") == (req.Login == ""):
+		writeInvalidField(w, r, "channel_id")
+		return
+	case req.ChannelID != "" && !validChannelID(req.ChannelID):
+		writeInvalidField(w, r, "channel_id")
+		return
+	case !validInterval(req.IntervalSeconds):
+		writeInvalidField(w, r, "interval_seconds")
+		return
+	}
+
+	entry := model.WatchlistEntry{
+		ChannelID:       req.ChannelID,
+		IntervalSeconds: req.IntervalSeconds,
+		Tags:            req.Tags,
+		Notes:           req.Notes,
+	}
+
+	if req.Login != "" {
+		timeout := h.Timeout
+		if timeout <= 0 {
+			timeout = defaultRequestTimeout
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		user, err := h.Users.FetchUserByLogin(ctx, req.Login)
+		if err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+		entry.ChannelID, entry.Login = user.ID, user.Login
+	}
+
+	entry, err := h.Watchlist.Add(entry)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/watchlist/"+entry.ChannelID)
+	writeJSONStatus(w, r, http.StatusCreated, entry)
+}
+
+// GetWatchlistEntryHandler handler to return a single watchlist entry given
+// its channel ID (path parameter).
+func (h *WatchlistHandler) GetWatchlistEntryHandler(w http.ResponseWriter, r *http.Request) {
+	entry, err := h.Watchlist.Get(mux.Vars(r)["channel_id"])
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, entry)
+}
+
+// UpdateWatchlistHandler handler to change the interval, tags or notes of a
+// watchlist entry; fields left out of the body are unchanged.
+func (h *WatchlistHandler) UpdateWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	var patch model.WatchlistPatch
+	if !decodeBody(w, r, &patch) {
+		return
+	}
+	if patch.IntervalSeconds != nil && !validInterval(*patch.IntervalSeconds) {
+		writeInvalidField(w, r, "interval_seconds")
+		return
+	}
+
+	entry, err := h.Watchlist.Update(mux.Vars(r)["channel_id"], patch)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, entry)
+}
+
+// RemoveWatchlistHandler handler to remove a channel from the watchlist,
+// responding 204.
+func (h *WatchlistHandler) RemoveWatchlistHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.Watchlist.Remove(mux.Vars(r)["channel_id"]); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeBody decodes the JSON request body into v, rejecting unknown fields,
+// and writes a 400 problem if it cannot
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWatchlistBody))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		writeProblem(w, r, model.Problem{
+			Type:   problemType(problemInvalidBody),
+			Status: http.StatusBadRequest,
+			Detail: "Invalid request body: " + err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// validChannelID reports whether id looks like a numeric Twitch channel id
+func validChannelID(id string) bool {
+	if id == "" || len(id) > maxChannelIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validInterval reports whether seconds is a poll interval a watchlist entry
+// may ask for, zero meaning the default
+func validInterval(seconds int) bool {
+	return seconds == 0 || time.Duration(seconds)*time.Second >= watchlist.MinInterval
+}
@@ -0,0 +1,179 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fourthfloor/internal/handlers"
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/twitch"
+	"fourthfloor/internal/watchlist"
+
+	"github.com/gorilla/mux"
+)
+
+// ---- Mocks ----
+
+// mockUserResolver resolves every login to channel 4242, except "missing"
+type mockUserResolver struct{}
+
+func (mockUserResolver) FetchUserByLogin(ctx context.Context, login string) (model.User, error) {
+	if login == "missing" {
+		return model.User{}, &twitch.APIError{StatusCode: http.StatusNotFound}
+	}
+	return model.User{ID: "4242", Login: strings.ToLower(login)}, nil
+}
+
+// newWatchlistRouter returns a router serving a watchlist kept in a temporary directory
+func newWatchlistRouter(t *testing.T) (*mux.Router, *watchlist.Store) {
+	t.Helper()
+	store, err := watchlist.Open(filepath.Join(t.TempDir(), "watchlist.json"))
+	if err != nil {
+		t.Fatalf("open watchlist: %v", err)
+	}
+
+	h := &handlers.WatchlistHandler{Watchlist: store, Users: mockUserResolver{}}
+	r := mux.NewRouter()
+	r.HandleFunc("/watchlist", h.ListWatchlistHandler).Methods("GET")
+	r.HandleFunc("/watchlist", h.AddWatchlistHandler).Methods("POST")
+	r.HandleFunc("/watchlist/{channel_id}", h.GetWatchlistEntryHandler).Methods("GET")
+	r.HandleFunc("/watchlist/{channel_id}", h.UpdateWatchlistHandler).Methods("PATCH")
+	r.HandleFunc("/watchlist/{channel_id}", h.RemoveWatchlistHandler).Methods("DELETE")
+	return r, store
+}
+
+// serve sends a request with an optional JSON body to r
+func serve(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+// ---- Tests ----
+
+func TestAddWatchlistHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode int
+		expectedType string
+		expectedID   string
+	}{
+		{name: "by channel id", body: `{"channel_id":"12826","interval_seconds":300,"tags":["Esports"],"notes":"main"}`, expectedCode: http.StatusCreated, expectedID: "12826"},
+		{name: "by login", body: `{"login":"TwitchDev"}`, expectedCode: http.StatusCreated, expectedID: "4242"},
+		{name: "unknown login", body: `{"login":"missing"}`, expectedCode: http.StatusNotFound, expectedType: "/problems/channel-not-found"},
+		{name: "neither id nor login", body: `{"notes":"x"}`, expectedCode: http.StatusBadRequest, expectedType: "/problems/invalid-body"},
+		{name: "both id and login", body: `{"channel_id":"1","login":"a"}`, expectedCode: http.StatusBadRequest, expectedType: "/problems/invalid-body"},
+		{name: "non numeric id", body: `{"channel_id":"abc"}`, expectedCode: http.StatusBadRequest, expectedType: "/problems/invalid-body"},
+		{name: "interval too short", body: `{"channel_id":"1","interval_seconds":5}`, expectedCode: http.StatusBadRequest, expectedType: "/problems/invalid-body"},
+		{name: "unknown field", body: `{"channel_id":"1","intervl":60}`, expectedCode: http.StatusBadRequest, expectedType: "/problems/invalid-body"},
+		{name: "malformed", body: `{`, expectedCode: http.StatusBadRequest, expectedType: "/problems/invalid-body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, store := newWatchlistRouter(t)
+			rec := serve(r, "POST", "/watchlist", tt.body)
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedCode, rec.Code, rec.Body)
+			}
+			if tt.expectedType != "" {
+				if p := decodeProblem(t, rec); p.Type != tt.expectedType {
+					t.Errorf("expected type %s, got %s", tt.expectedType, p.Type)
+				}
+				if len(store.List()) != 0 {
+					t.Errorf("expected nothing added, got %+v", store.List())
+				}
+				return
+			}
+
+			var entry model.WatchlistEntry
+			if err := json.NewDecoder(rec.Body).Decode(&entry); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if entry.ChannelID != tt.expectedID || rec.Header().Get("Location") != "/watchlist/"+tt.expectedID {
+				t.Errorf("unexpected entry %+v at %s", entry, rec.Header().Get("Location"))
+			}
+			if _, err := store.Get(tt.expectedID); err != nil {
+				t.Errorf("expected entry stored: %v", err)
+			}
+		})
+	}
+}
+
+func TestWatchlistHandlerLifecycle(t *testing.T) {
+	r, _ := newWatchlistRouter(t)
+
+	serve(r, "POST", "/watchlist", `{"channel_id":"1","tags":["Vods"]}`)
+	serve(r, "POST", "/watchlist", `{"channel_id":"2"}`)
+
+	if rec := serve(r, "POST", "/watchlist", `{"channel_id":"1"}`); rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 adding a watched channel, got %d", rec.Code)
+	}
+
+	rec := serve(r, "GET", "/watchlist?tag=VODs", "")
+	var list model.WatchlistResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil || len(list.Channels) != 1 || list.Channels[0].ChannelID != "1" {
+		t.Errorf("expected channels filtered by tag, got %s", rec.Body)
+	}
+
+	rec = serve(r, "PATCH", "/watchlist/2", `{"interval_seconds":120,"notes":"new"}`)
+	var entry model.WatchlistEntry
+	if err := json.NewDecoder(rec.Body).Decode(&entry); err != nil || entry.IntervalSeconds != 120 || entry.Notes != "new" {
+		t.Errorf("expected entry updated, got %d %s", rec.Code, rec.Body)
+	}
+	if rec := serve(r, "PATCH", "/watchlist/2", `{"interval_seconds":-1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a negative interval, got %d", rec.Code)
+	}
+
+	if rec := serve(r, "DELETE", "/watchlist/2", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", rec.Code)
+	}
+
+	for _, req := range [][2]string{{"GET", "/watchlist/2"}, {"PATCH", "/watchlist/2"}, {"DELETE", "/watchlist/2"}} {
+		rec := serve(r, req[0], req[1], `{}`)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", req[0], rec.Code)
+			continue
+		}
+		if p := decodeProblem(t, rec); p.Type != "/problems/not-watched" {
+			t.Errorf("%s: expected not-watched problem, got %s", req[0], p.Type)
+		}
+	}
+
+	rec = serve(r, "GET", "/watchlist", "")
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil || len(list.Channels) != 1 {
+		t.Errorf("expected one channel left, got %s", rec.Body)
+	}
+}
+
+func TestWatchlistHandlerSaveFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	store, err := watchlist.Open(filepath.Join(dir, "watchlist.json"))
+	if err != nil {
+		t.Fatalf("open watchlist: %v", err)
+	}
+	os.RemoveAll(dir)
+
+	h := &handlers.WatchlistHandler{Watchlist: store, Users: mockUserResolver{}}
+	rec := serve(http.HandlerFunc(h.AddWatchlistHandler), "POST", "/watchlist", `{"channel_id":"1"}`)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d: %s", rec.Code, rec.Body)
+	}
+	// the filesystem error is logged, not shown
+	if p := decodeProblem(t, rec); p.Type != "/problems/internal" || p.Detail != "Internal error" {
+		t.Errorf("expected a generic internal problem, got %+v", p)
+	}
+}
@@ -0,0 +1,135 @@
+package model
+
+import "time"
+
+// VideoTypeAll matches every video type when filtering
+const VideoTypeAll VideoType = "all"
+
+// VideoPeriod time period a video filter covers
+type VideoPeriod string
+
+const (
+	VideoPeriodAll   VideoPeriod = "all"
+	VideoPeriodDay   VideoPeriod = "day"
+	VideoPeriodWeek  VideoPeriod = "week"
+	VideoPeriodMonth VideoPeriod = "month"
+)
+
+// VideoSort order videos are returned in
+type VideoSort string
+
+const (
+	VideoSortTime     VideoSort = "time"
+	VideoSortTrending VideoSort = "trending"
+	VideoSortViews    VideoSort = "views"
+)
+
+// VideoFilter optional filters supported by the Helix videos endpoint.
+// Zero values are not sent, leaving Twitch to apply its defaults. Helix only
+// filters by language together with a game, so Language is matched against the
+// fetched videos instead.
+type VideoFilter struct {
+	Type     VideoType
+	Period   VideoPeriod
+	Sort     VideoSort
+	Language string
+}
+
+// Valid reports whether t is a type accepted by the Helix videos filter
+func (t VideoType) Valid() bool {
+	switch t {
+	case "", VideoTypeAll, VideoTypeArchive, VideoTypeHighlight, VideoTypeUpload:
+		return true
+	}
+	return false
+}
+
+// Valid reports whether p is a period accepted by the Helix videos filter
+func (p VideoPeriod) Valid() bool {
+	switch p {
+	case "", VideoPeriodAll, VideoPeriodDay, VideoPeriodWeek, VideoPeriodMonth:
+		return true
+	}
+	return false
+}
+
+// Valid reports whether s is a sort order accepted by the Helix videos filter
+func (s VideoSort) Valid() bool {
+	switch s {
+	case "", VideoSortTime, VideoSortTrending, VideoSortViews:
+		return true
+	}
+	return false
+}
+
+// ValidLanguage reports whether lang is accepted by the Helix videos filter:
+// a two-letter ISO 639-1 code or "other".
+func ValidLanguage(lang string) bool {
+	if lang == "" || lang == "other" {
+		return true
+	}
+	if len(lang) != 2 {
+		return false
+	}
+	for _, r := range lang {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// RankMetric per-video metric videos are ranked by
+type RankMetric string
+
+const (
+	RankByViews          RankMetric = "views"
+	RankByViewsPerMinute RankMetric = "views_per_minute"
+	RankByViewsPerDay    RankMetric = "views_per_day"
+	RankByDuration       RankMetric = "duration"
+)
+
+// Valid reports whether m is a known rank metric, empty meaning the default
+func (m RankMetric) Valid() bool {
+	switch m {
+	case "", RankByViews, RankByViewsPerMinute, RankByViewsPerDay, RankByDuration:
+		return true
+	}
+	return false
+}
+
+// Value returns the metric's value for a video summary
+func (m RankMetric) Value(v VideoSummary) float64 {
+	switch m {
+	case RankByViewsPerMinute:
+		return v.ViewsPerMinute
+	case RankByViewsPerDay:
+		return v.ViewsPerDay
+	case RankByDuration:
+		return v.DurationMinutes
+	}
+	return float64(v.ViewCount)
+}
+
+// StatsQuery parameters for a video stats request. Limit caps the number of
+// videos aggregated; Since and Until, when set, restrict the aggregation to
+// videos created within [Since, Until). IncludeVideos adds the per-video
+// summaries to the response. Top and Bottom request ranked lists of the best
+// and worst videos by RankBy. FirstDays is the number of days after publishing
+// views are reported for when snapshots are recorded.
+type StatsQuery struct {
+	Limit         int
+	Filter        VideoFilter
+	Since         time.Time
+	Until         time.Time
+	IncludeVideos bool
+	Top           int
+	Bottom        int
+	RankBy        RankMetric
+	FirstDays     int
+}
+
+// Windowed reports whether the query restricts videos by creation date
+func (q StatsQuery) Windowed() bool {
+	return !q.Since.IsZero() || !q.Until.IsZero()
+}
@@ -0,0 +1,35 @@
+package model_test
+
+import (
+	"fourthfloor/internal/model"
+	"testing"
+)
+
+func TestVideoFilterValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+		got   bool
+	}{
+		{name: "empty type", valid: true, got: model.VideoType("").Valid()},
+		{name: "archive type", valid: true, got: model.VideoTypeArchive.Valid()},
+		{name: "all type", valid: true, got: model.VideoTypeAll.Valid()},
+		{name: "unknown type", valid: false, got: model.VideoType("clip").Valid()},
+		{name: "week period", valid: true, got: model.VideoPeriodWeek.Valid()},
+		{name: "unknown period", valid: false, got: model.VideoPeriod("year").Valid()},
+		{name: "views sort", valid: true, got: model.VideoSortViews.Valid()},
+		{name: "unknown sort", valid: false, got: model.VideoSort("random").Valid()},
+		{name: "two letter language", valid: true, got: model.ValidLanguage("en")},
+		{name: "other language", valid: true, got: model.ValidLanguage("other")},
+		{name: "uppercase language", valid: false, got: model.ValidLanguage("EN")},
+		{name: "long language", valid: false, got: model.ValidLanguage("english")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.valid {
+				t.Errorf("wanted valid=%v, got %v", tt.valid, tt.got)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package model
+
+import "time"
+
+// HistoryResolution granularity a video's view history is downsampled to
+type HistoryResolution string
+
+const (
+	HistoryResolutionRaw  HistoryResolution = "raw"
+	HistoryResolutionHour HistoryResolution = "hour"
+	HistoryResolutionDay  HistoryResolution = "day"
+)
+
+// Valid reports whether r is a known resolution, empty meaning the default
+func (r HistoryResolution) Valid() bool {
+	switch r {
+	case "", HistoryResolutionRaw, HistoryResolutionHour, HistoryResolutionDay:
+		return true
+	}
+	return false
+}
+
+// Bucket returns the width of the buckets snapshots are downsampled into,
+// zero for raw
+func (r HistoryResolution) Bucket() time.Duration {
+	switch r {
+	case HistoryResolutionHour:
+		return time.Hour
+	case HistoryResolutionDay:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// HistoryQuery parameters for a view history request. Since and Until, when
+// set, restrict the returned points to [Since, Until). FirstDays is the number
+// of days after publishing ViewsFirstDays is reported for.
+type HistoryQuery struct {
+	Resolution HistoryResolution
+	Since      time.Time
+	Until      time.Time
+	FirstDays  int
+}
+
+// HistoryPoint view count of a video at a point in its history, with the views
+// gained since the previous point and the rate they were gained at
+type HistoryPoint struct {
+	At           time.Time `json:"at"`
+	ViewCount    int       `json:"view_count"`
+	ViewsGained  int       `json:"views_gained"`
+	ViewsPerHour float64   `json:"views_per_hour"`
+}
+
+// Velocity rate a video gained views at, between the previous point and At
+type Velocity struct {
+	ViewsPerHour float64   `json:"views_per_hour"`
+	At           time.Time `json:"at"`
+}
+
+// VideoHistoryResponse response model for a video's view history. ViewsGained,
+// ViewsPerHour, ViewsPerDay and PeakVelocity cover the returned points;
+// CurrentViews, the time to reach 50% and 90% of them (in hours since
+// publishing) and ViewsFirstDays use every snapshot, and are nil when the
+// snapshots cannot tell.
+type VideoHistoryResponse struct {
+	VideoID          string            `json:"video_id"`
+	ChannelID        string            `json:"channel_id"`
+	Title            string            `json:"title"`
+	PublishedAt      time.Time         `json:"published_at"`
+	Resolution       HistoryResolution `json:"resolution"`
+	Snapshots        int               `json:"snapshots"`
+	CurrentViews     int               `json:"current_views"`
+	ViewsGained      int               `json:"views_gained"`
+	ViewsPerHour     float64           `json:"views_per_hour"`
+	ViewsPerDay      float64           `json:"views_per_day"`
+	PeakVelocity     *Velocity         `json:"peak_velocity"`
+	HoursTo50Percent *float64          `json:"hours_to_50_percent"`
+	HoursTo90Percent *float64          `json:"hours_to_90_percent"`
+	FirstDays        int               `json:"first_days"`
+	ViewsFirstDays   *int              `json:"views_first_days"`
+	Points           []HistoryPoint    `json:"points"`
+}
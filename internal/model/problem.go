@@ -0,0 +1,15 @@
+package model
+
+// Problem RFC 7807 problem details body returned for every error. Type is a
+// stable, machine-readable URI reference of the form "/problems/<code>";
+// UpstreamStatus is the status Twitch responded with when the error came from
+// Twitch.
+type Problem struct {
+	Type           string `json:"type"`
+	Title          string `json:"title"`
+	Status         int    `json:"status"`
+	Detail         string `json:"detail,omitempty"`
+	Instance       string `json:"instance,omitempty"`
+	RequestID      string `json:"request_id,omitempty"`
+	UpstreamStatus int    `json:"upstream_status,omitempty"`
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// Snapshot view count of a video at the time it was fetched
+type Snapshot struct {
+	VideoID   string    `json:"video_id"`
+	ChannelID string    `json:"channel_id"`
+	ViewCount int       `json:"view_count"`
+	At        time.Time `json:"at"`
+}
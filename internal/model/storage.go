@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// CompactionResult outcome of a snapshot compaction run
+type CompactionResult struct {
+	At              time.Time `json:"at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Videos          int       `json:"videos"`
+	Removed         int       `json:"removed"`
+}
+
+// StorageStats size of the snapshot store. FreeBytes is space in the file
+// left by removed snapshots, reused before the file grows again.
+type StorageStats struct {
+	Videos         int               `json:"videos"`
+	Snapshots      int               `json:"snapshots"`
+	FileSizeBytes  int64             `json:"file_size_bytes"`
+	FreeBytes      int64             `json:"free_bytes"`
+	LastCompaction *CompactionResult `json:"last_compaction"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// BroadcasterType partner status of a Twitch user
+type BroadcasterType string
+
+const (
+	BroadcasterTypePartner   BroadcasterType = "partner"
+	BroadcasterTypeAffiliate BroadcasterType = "affiliate"
+	BroadcasterTypeNormal    BroadcasterType = ""
+)
+
+// User model for a single user as returned by the Helix users endpoint
+type User struct {
+	ID              string          `json:"id"`
+	Login           string          `json:"login"`
+	DisplayName     string          `json:"display_name"`
+	Type            string          `json:"type"`
+	BroadcasterType BroadcasterType `json:"broadcaster_type"`
+	Description     string          `json:"description"`
+	ProfileImageURL string          `json:"profile_image_url"`
+	OfflineImageURL string          `json:"offline_image_url"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// UserResponse response model for call to Twitch users API
+type UserResponse struct {
+	Data []User `json:"data"`
+}
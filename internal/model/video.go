@@ -1,23 +1,164 @@
 package model
 
-// Video model for single video
+import "time"
+
+// VideoType type of a Twitch video
+type VideoType string
+
+const (
+	VideoTypeArchive   VideoType = "archive"
+	VideoTypeHighlight VideoType = "highlight"
+	VideoTypeUpload    VideoType = "upload"
+)
+
+// Viewable visibility of a Twitch video
+type Viewable string
+
+const (
+	ViewablePublic  Viewable = "public"
+	ViewablePrivate Viewable = "private"
+)
+
+// MutedSegment segment of a video that has been muted for copyrighted audio.
+// Duration and Offset are in seconds.
+type MutedSegment struct {
+	Duration int `json:"duration"`
+	Offset   int `json:"offset"`
+}
+
+// Video model for single video as returned by the Helix videos endpoint
 type Video struct {
-	Title     string `json:"title"`
-	ViewCount int    `json:"view_count"`
-	Duration  string `json:"duration"`
+	ID            string         `json:"id"`
+	StreamID      string         `json:"stream_id,omitempty"`
+	UserID        string         `json:"user_id"`
+	UserLogin     string         `json:"user_login"`
+	UserName      string         `json:"user_name"`
+	Title         string         `json:"title"`
+	Description   string         `json:"description"`
+	CreatedAt     time.Time      `json:"created_at"`
+	PublishedAt   time.Time      `json:"published_at"`
+	URL           string         `json:"url"`
+	ThumbnailURL  string         `json:"thumbnail_url"`
+	Viewable      Viewable       `json:"viewable"`
+	ViewCount     int            `json:"view_count"`
+	Language      string         `json:"language"`
+	Type          VideoType      `json:"type"`
+	Duration      string         `json:"duration"`
+	MutedSegments []MutedSegment `json:"muted_segments,omitempty"`
+}
+
+// Pagination cursor block returned by paginated Twitch API calls
+type Pagination struct {
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // VideoResponse response model for call to Twitch API
 type VideoResponse struct {
-	Data []Video `json:"data"`
+	Data       []Video    `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// StatsWindow effective date window stats were computed over. Open bounds in the
+// request are filled from the oldest/newest video aggregated. Truncated is set when
+// the video cap was reached before the lower bound.
+type StatsWindow struct {
+	Since      time.Time `json:"since"`
+	Until      time.Time `json:"until"`
+	VideoCount int       `json:"video_count"`
+	Truncated  bool      `json:"truncated"`
+}
+
+// Distribution summary statistics over a set of per-video values. Percentiles
+// are linearly interpolated and StdDev is the population standard deviation.
+type Distribution struct {
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P25    float64 `json:"p25"`
+	P75    float64 `json:"p75"`
+	P90    float64 `json:"p90"`
+	P99    float64 `json:"p99"`
+	StdDev float64 `json:"stddev"`
+}
+
+// VideoSummary per-video metrics derived from a Video. PublishedAt falls back to
+// the creation time for unpublished videos; AgeDays is measured from it and
+// ViewsPerDay normalizes views by that age.
+type VideoSummary struct {
+	ID              string    `json:"id"`
+	Title           string    `json:"title"`
+	URL             string    `json:"url"`
+	PublishedAt     time.Time `json:"published_at"`
+	ViewCount       int       `json:"view_count"`
+	DurationMinutes float64   `json:"duration_minutes"`
+	ViewsPerMinute  float64   `json:"views_per_minute"`
+	AgeDays         float64   `json:"age_days"`
+	ViewsPerDay     float64   `json:"views_per_day"`
+	ViewsFirstDays  *int      `json:"views_first_days,omitempty"`
 }
 
 // VideoStatsResponse response model for video stats
 type VideoStatsResponse struct {
-	TotalViews           int     `json:"total_views"`
-	AverageViews         float64 `json:"average_views"`
-	TotalDurationMinutes float64 `json:"total_duration_minutes"`
-	AvgViewsPerMinute    float64 `json:"views_per_minute"`
-	MostViewedTitle      string  `json:"most_viewed_title"`
-	MostViewedViewCount  int     `json:"most_viewed_view_count"`
+	TotalViews           int            `json:"total_views"`
+	AverageViews         float64        `json:"average_views"`
+	TotalDurationMinutes float64        `json:"total_duration_minutes"`
+	AvgViewsPerMinute    float64        `json:"views_per_minute"`
+	MostViewedTitle      string         `json:"most_viewed_title"`
+	MostViewedViewCount  int            `json:"most_viewed_view_count"`
+	MinViewedTitle       string         `json:"min_viewed_title"`
+	MinViewedViewCount   int            `json:"min_viewed_view_count"`
+	Views                Distribution   `json:"views_distribution"`
+	DurationMinutes      Distribution   `json:"duration_minutes_distribution"`
+	ViewsPerDay          Distribution   `json:"views_per_day_distribution"`
+	FirstDays            int            `json:"first_days,omitempty"`
+	ViewsFirstDays       *Distribution  `json:"views_first_days_distribution,omitempty"`
+	User                 *User          `json:"user,omitempty"`
+	Window               *StatsWindow   `json:"window,omitempty"`
+	Videos               []VideoSummary `json:"videos,omitempty"`
+	Top                  []VideoSummary `json:"top,omitempty"`
+	Bottom               []VideoSummary `json:"bottom,omitempty"`
+}
+
+// Delta change in a single stats field between two periods. Percent is nil when
+// the previous value is zero.
+type Delta struct {
+	Current  float64  `json:"current"`
+	Previous float64  `json:"previous"`
+	Absolute float64  `json:"absolute"`
+	Percent  *float64 `json:"percent"`
+}
+
+// VideoGrowthResponse response model comparing stats for a period with the
+// preceding equivalent period. Deltas are keyed by the JSON name of each numeric
+// stats field, nested fields joined with a dot (e.g. "views_distribution.median"),
+// and omitted when there is no complete previous period to compare with.
+type VideoGrowthResponse struct {
+	Current  VideoStatsResponse `json:"current"`
+	Previous VideoStatsResponse `json:"previous"`
+	Deltas   map[string]Delta   `json:"deltas,omitempty"`
+}
+
+// ChannelStats stats for one channel in a comparison. Error is set instead of
+// Stats when the channel's stats could not be fetched.
+type ChannelStats struct {
+	ChannelID string              `json:"channel_id"`
+	Stats     *VideoStatsResponse `json:"stats,omitempty"`
+	Error     string              `json:"error,omitempty"`
+}
+
+// RankingEntry position of a channel in a comparison ranking
+type RankingEntry struct {
+	Rank      int     `json:"rank"`
+	ChannelID string  `json:"channel_id"`
+	Value     float64 `json:"value"`
+}
+
+// ComparisonResponse response model for comparing stats across channels.
+// Channels are in request order; Ranking orders the successful channels by
+// Metric, highest first.
+type ComparisonResponse struct {
+	Metric   string         `json:"metric"`
+	Channels []ChannelStats `json:"channels"`
+	Ranking  []RankingEntry `json:"ranking"`
 }
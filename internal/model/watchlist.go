@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// WatchlistEntry channel polled in the background for view count snapshots.
+// A zero IntervalSeconds polls at the default interval.
+type WatchlistEntry struct {
+	ChannelID       string    `json:"channel_id"`
+	Login           string    `json:"login,omitempty"`
+	IntervalSeconds int       `json:"interval_seconds,omitempty"`
+	Tags            []string  `json:"tags,omitempty"`
+	Notes           string    `json:"notes,omitempty"`
+	AddedAt         time.Time `json:"added_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// WatchlistRequest body of a request adding a channel to the watchlist, by
+// exactly one of ChannelID or Login
+type WatchlistRequest struct {
+	ChannelID       string   `json:"channel_id"`
+	Login           string   `json:"login"`
+	IntervalSeconds int      `json:"interval_seconds"`
+	Tags            []string `json:"tags"`
+	Notes           string   `json:"notes"`
+}
+
+// WatchlistPatch body of a request updating a watchlist entry; fields left
+// out of the body are unchanged
+type WatchlistPatch struct {
+	IntervalSeconds *int      `json:"interval_seconds"`
+	Tags            *[]string `json:"tags"`
+	Notes           *string   `json:"notes"`
+}
+
+// WatchlistResponse response model for listing the watchlist
+type WatchlistResponse struct {
+	Channels []WatchlistEntry `json:"channels"`
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"fourthfloor/internal/storage"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultCompactInterval is how often snapshots are compacted
+const DefaultCompactInterval = time.Hour
+
+// CompactionJob rolls up old snapshots under a RetentionPolicy, once on start
+// and then at an interval.
+type CompactionJob struct {
+	store    storage.Compactor
+	policy   storage.RetentionPolicy
+	interval time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex // protects stop
+	stop func()
+}
+
+// NewCompactionJob creates a CompactionJob compacting store under policy every hour.
+func NewCompactionJob(store storage.Compactor, policy storage.RetentionPolicy, options ...func(*CompactionJob)) *CompactionJob {
+	j := &CompactionJob{
+		store:    store,
+		policy:   policy,
+		interval: DefaultCompactInterval,
+		now:      time.Now,
+	}
+
+	for _, opt := range options {
+		opt(j)
+	}
+	return j
+}
+
+// WithCompactInterval sets how often snapshots are compacted. A non-positive
+// interval is ignored, keeping the default.
+func WithCompactInterval(d time.Duration) func(*CompactionJob) {
+	return func(j *CompactionJob) {
+		if d > 0 {
+			j.interval = d
+		}
+	}
+}
+
+// Start compacts immediately and then every interval until ctx is cancelled or
+// Stop is called. Calling it while the job is already running has no effect.
+func (j *CompactionJob) Start(ctx context.Context) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.stop != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			j.Run(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	j.stop = func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// Stop stops the job, interrupting a compaction between videos, and waits for it to exit.
+func (j *CompactionJob) Stop() {
+	j.mu.Lock()
+	stop := j.stop
+	j.stop = nil
+	j.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+// Run compacts once, logging the outcome.
+func (j *CompactionJob) Run(ctx context.Context) {
+	result, err := j.store.Compact(ctx, j.now(), j.policy)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("snapshot compaction failed: %v", err)
+		}
+		return
+	}
+	if result.Removed > 0 {
+		log.Printf("snapshot compaction removed %d snapshots across %d videos in %.1fs", result.Removed, result.Videos, result.DurationSeconds)
+	}
+}
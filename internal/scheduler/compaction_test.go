@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/storage"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ---- Mocks ----
+
+// mockCompactor counts compactions, recording the policy and time of the last
+type mockCompactor struct {
+	mu     sync.Mutex
+	runs   int
+	policy storage.RetentionPolicy
+	at     time.Time
+}
+
+func (m *mockCompactor) Compact(ctx context.Context, now time.Time, policy storage.RetentionPolicy) (model.CompactionResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs++
+	m.policy, m.at = policy, now
+	return model.CompactionResult{At: now}, ctx.Err()
+}
+
+func (m *mockCompactor) runCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.runs
+}
+
+// ---- Tests ----
+
+func TestCompactionJob(t *testing.T) {
+	store := &mockCompactor{}
+	policy := storage.RetentionPolicy{Raw: time.Hour, Hourly: 24 * time.Hour}
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	j := NewCompactionJob(store, policy, WithCompactInterval(10*time.Millisecond))
+	j.now = func() time.Time { return now }
+	j.Start(context.Background())
+	j.Start(context.Background()) // no effect while running
+
+	waitFor(t, "compaction on start and on each tick", func() bool { return store.runCount() >= 3 })
+	j.Stop()
+	j.Stop() // no effect once stopped
+
+	runs := store.runCount()
+	time.Sleep(30 * time.Millisecond)
+	if store.runCount() != runs {
+		t.Error("wanted no compaction after Stop")
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.policy != policy || !store.at.Equal(now) {
+		t.Errorf("wanted compaction under the job's policy at now, got %+v at %s", store.policy, store.at)
+	}
+}
+
+func TestCompactionJobNonPositiveInterval(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Minute} {
+		j := NewCompactionJob(&mockCompactor{}, storage.DefaultRetention, WithCompactInterval(d))
+		if j.interval != DefaultCompactInterval {
+			t.Errorf("interval %s: wanted default %s kept, got %s", d, DefaultCompactInterval, j.interval)
+		}
+
+		// a ticker with a non-positive interval would panic
+		j.Start(context.Background())
+		j.Stop()
+	}
+}
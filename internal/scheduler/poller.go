@@ -0,0 +1,315 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/storage"
+	"fourthfloor/internal/twitch"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultInterval is how often a target without its own interval is polled
+	DefaultInterval = 15 * time.Minute
+
+	// defaultVideosPerPoll is how many of a channel's latest videos each poll
+	// fetches, a single Helix page
+	defaultVideosPerPoll = 100
+
+	// defaultReserve is the fraction of the Helix rate budget kept for API
+	// requests; polls are deferred while less than this remains
+	defaultReserve = 0.1
+
+	// minBudgetWait is the shortest a poll is deferred for lack of budget
+	minBudgetWait = time.Second
+)
+
+// Target channel polled at an interval, DefaultInterval or the Poller's own if zero
+type Target struct {
+	ChannelID string
+	Interval  time.Duration
+}
+
+// budgeter client that reports its Helix rate budget, like *twitch.TwitchAPIClient
+type budgeter interface {
+	RateLimit() twitch.RateLimitState
+}
+
+// Poller polls the latest videos of a set of channels, each at its own
+// interval, and records them in a SnapshotStore. Channels are polled one at a
+// time, and polls are deferred while the client's rate budget is below the
+// reserve kept for API requests.
+type Poller struct {
+	client   twitch.TwitchAPIClientInterface
+	store    storage.SnapshotStore
+	interval time.Duration
+	videos   int
+	reserve  float64
+	budget   func() twitch.RateLimitState
+	now      func() time.Time
+
+	mu      sync.Mutex // protects targets and stop
+	targets map[string]*scheduled
+	wake    chan struct{}
+	stop    func()
+}
+
+// scheduled target with the time it was last polled and is next due
+type scheduled struct {
+	Target
+	last time.Time
+	next time.Time
+}
+
+// NewPoller creates a Poller fetching videos with client and recording them in
+// store. If client reports its rate budget, as *twitch.TwitchAPIClient does,
+// polls respect it. Pass client undecorated: a cache would hide new view
+// counts and a storage.RecordingClient would record them twice.
+func NewPoller(client twitch.TwitchAPIClientInterface, store storage.SnapshotStore, options ...func(*Poller)) *Poller {
+	p := &Poller{
+		client:   client,
+		store:    store,
+		interval: DefaultInterval,
+		videos:   defaultVideosPerPoll,
+		reserve:  defaultReserve,
+		now:      time.Now,
+		targets:  make(map[string]*scheduled),
+		wake:     make(chan struct{}, 1),
+	}
+	if b, ok := client.(budgeter); ok {
+		p.budget = b.RateLimit
+	}
+
+	for _, opt := range options {
+		opt(p)
+	}
+	return p
+}
+
+// WithInterval sets how often targets without their own interval are polled.
+// A non-positive interval is ignored, keeping the default.
+func WithInterval(d time.Duration) func(*Poller) {
+	return func(p *Poller) {
+		if d > 0 {
+			p.interval = d
+		}
+	}
+}
+
+// WithVideosPerPoll sets how many of a channel's latest videos each poll fetches.
+func WithVideosPerPoll(n int) func(*Poller) {
+	return func(p *Poller) { p.videos = n }
+}
+
+// WithReserve sets the fraction of the rate budget kept for API requests.
+func WithReserve(fraction float64) func(*Poller) {
+	return func(p *Poller) { p.reserve = fraction }
+}
+
+// WithBudget sets where the rate budget is read from, overriding the client's.
+func WithBudget(budget func() twitch.RateLimitState) func(*Poller) {
+	return func(p *Poller) { p.budget = budget }
+}
+
+// SetTargets replaces the polled channels. New channels are polled right away;
+// channels kept keep their schedule, moved to the new interval counting from
+// their last poll.
+func (p *Poller) SetTargets(targets []Target) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	current := make(map[string]*scheduled, len(targets))
+	for _, t := range targets {
+		if t.ChannelID == "" {
+			continue
+		}
+
+		s, ok := p.targets[t.ChannelID]
+		if !ok {
+			s = &scheduled{next: now}
+		}
+		s.Target = t
+		if !s.last.IsZero() {
+			s.next = s.last.Add(p.intervalOf(t))
+		}
+		current[t.ChannelID] = s
+	}
+	p.targets = current
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Targets returns the polled channels ordered by channel id.
+func (p *Poller) Targets() []Target {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	targets := make([]Target, 0, len(p.targets))
+	for _, s := range p.targets {
+		targets = append(targets, s.Target)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].ChannelID < targets[j].ChannelID })
+	return targets
+}
+
+// Start polls targets in the background until ctx is cancelled or Stop is
+// called. Calling it while the poller is already running has no effect.
+func (p *Poller) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stop != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		p.run(ctx)
+	}()
+
+	p.stop = func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// Stop stops the poller, cancelling any poll in flight, and waits for it to exit.
+func (p *Poller) Stop() {
+	p.mu.Lock()
+	stop := p.stop
+	p.stop = nil
+	p.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+// run polls the next due target until ctx is done
+func (p *Poller) run(ctx context.Context) {
+	for {
+		channelID, wait := p.nextDue()
+		if channelID != "" && wait <= 0 {
+			wait = p.budgetWait()
+		}
+		if channelID != "" && wait <= 0 {
+			p.poll(ctx, channelID)
+			continue
+		}
+
+		// without targets, sleep until SetTargets wakes the loop
+		timer := time.NewTimer(wait)
+		if channelID == "" {
+			timer.Stop()
+		}
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-p.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// nextDue returns the target due soonest and how long until it is due, or an
+// empty id if there are no targets
+func (p *Poller) nextDue() (string, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var next *scheduled
+	for _, s := range p.targets {
+		if next == nil || s.next.Before(next.next) {
+			next = s
+		}
+	}
+	if next == nil {
+		return "", 0
+	}
+	return next.ChannelID, next.next.Sub(p.now())
+}
+
+// budgetWait returns how long until the rate budget is back above the
+// reserve, zero if it already is
+func (p *Poller) budgetWait() time.Duration {
+	if p.budget == nil {
+		return 0
+	}
+
+	state := p.budget()
+	reserve := int(float64(state.Limit) * p.reserve)
+	if state.Limit <= 0 || state.Remaining > reserve {
+		return 0
+	}
+
+	// the budget refills at Limit tokens per minute
+	wait := time.Duration(float64(reserve-state.Remaining+1) / float64(state.Limit) * float64(time.Minute))
+	if state.Remaining == 0 {
+		wait = max(wait, state.Reset.Sub(p.now()))
+	}
+	return max(wait, minBudgetWait)
+}
+
+// poll fetches and records a channel's latest videos, then schedules its next poll
+func (p *Poller) poll(ctx context.Context, channelID string) {
+	start := p.now()
+	next := p.record(ctx, channelID, start)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// the target may have been removed or replaced while polling
+	if s, ok := p.targets[channelID]; ok {
+		s.last = start
+		s.next = start.Add(p.intervalOf(s.Target))
+		if next > 0 {
+			s.next = p.now().Add(next)
+		}
+	}
+}
+
+// record fetches and records a channel's latest videos, returning how soon to
+// retry if Twitch asked to be retried sooner than the usual interval
+func (p *Poller) record(ctx context.Context, channelID string, at time.Time) time.Duration {
+	videos, err := p.client.FetchVideos(ctx, channelID, p.videos, model.VideoFilter{})
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0
+		}
+		log.Printf("poll channel %s: %v", channelID, err)
+
+		var limited interface{ RetryAfter() time.Duration }
+		if errors.Is(err, twitch.ErrRateLimited) && errors.As(err, &limited) {
+			return max(limited.RetryAfter(), minBudgetWait)
+		}
+		return 0
+	}
+
+	if err := p.store.Record(ctx, at, videos...); err != nil && ctx.Err() == nil {
+		log.Printf("record channel %s: %v", channelID, err)
+	}
+	return 0
+}
+
+// intervalOf returns how often t is polled
+func (p *Poller) intervalOf(t Target) time.Duration {
+	if t.Interval > 0 {
+		return t.Interval
+	}
+	return p.interval
+}
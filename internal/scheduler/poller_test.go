@@ -0,0 +1,241 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/storage"
+	"fourthfloor/internal/twitch"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ---- Mocks ----
+
+// mockTwitchClient counts polls per channel, optionally blocking until cancelled
+type mockTwitchClient struct {
+	mu    sync.Mutex
+	calls map[string]int
+	err   error
+	block bool
+}
+
+func (m *mockTwitchClient) FetchVideos(ctx context.Context, channelID string, limit int, filter model.VideoFilter) ([]model.Video, error) {
+	m.mu.Lock()
+	if m.calls == nil {
+		m.calls = make(map[string]int)
+	}
+	m.calls[channelID]++
+	m.mu.Unlock()
+
+	if m.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return []model.Video{{ID: channelID + "-video", UserID: channelID, ViewCount: limit}}, m.err
+}
+
+func (m *mockTwitchClient) FetchVideoPage(ctx context.Context, channelID string, first int, filter model.VideoFilter, cursor string) (model.VideoResponse, error) {
+	return model.VideoResponse{}, errors.New("not used")
+}
+
+func (m *mockTwitchClient) FetchUserByLogin(ctx context.Context, login string) (model.User, error) {
+	return model.User{}, errors.New("not used")
+}
+
+func (m *mockTwitchClient) callCount(channelID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[channelID]
+}
+
+// mockStore records the ids of every video recorded
+type mockStore struct {
+	storage.SnapshotStore
+
+	mu       sync.Mutex
+	recorded []string
+}
+
+func (s *mockStore) Record(ctx context.Context, at time.Time, videos ...model.Video) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, v := range videos {
+		s.recorded = append(s.recorded, v.ID)
+	}
+	return nil
+}
+
+func (s *mockStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.recorded)
+}
+
+// waitFor polls cond until it holds or a second has passed
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// ---- Tests ----
+
+func TestPollerPollsAtTargetIntervals(t *testing.T) {
+	client := &mockTwitchClient{}
+	store := &mockStore{}
+	p := NewPoller(client, store, WithInterval(time.Hour), WithVideosPerPoll(20))
+
+	p.SetTargets([]Target{{ChannelID: "fast", Interval: 10 * time.Millisecond}, {ChannelID: "slow"}})
+	p.Start(context.Background())
+	defer p.Stop()
+
+	waitFor(t, "fast channel polled 3 times", func() bool { return client.callCount("fast") >= 3 })
+	if got := client.callCount("slow"); got != 1 {
+		t.Errorf("wanted slow channel polled once, got %d", got)
+	}
+
+	p.Stop()
+	if store.count() != client.callCount("fast")+client.callCount("slow") {
+		t.Errorf("wanted every poll recorded, got %d records", store.count())
+	}
+}
+
+func TestPollerSetTargets(t *testing.T) {
+	client := &mockTwitchClient{}
+	p := NewPoller(client, &mockStore{})
+	p.Start(context.Background())
+	defer p.Stop()
+
+	// picked up while running, without a restart
+	p.SetTargets([]Target{{ChannelID: "b", Interval: time.Hour}, {ChannelID: "a"}, {ChannelID: ""}})
+	waitFor(t, "new targets polled", func() bool { return client.callCount("a") == 1 && client.callCount("b") == 1 })
+
+	got := p.Targets()
+	if len(got) != 2 || got[0].ChannelID != "a" || got[1] != (Target{ChannelID: "b", Interval: time.Hour}) {
+		t.Errorf("unexpected targets %+v", got)
+	}
+
+	// kept targets keep their schedule instead of being polled again
+	p.SetTargets([]Target{{ChannelID: "b", Interval: 2 * time.Hour}, {ChannelID: "c"}})
+	waitFor(t, "added target polled", func() bool { return client.callCount("c") == 1 })
+	if client.callCount("b") != 1 {
+		t.Errorf("wanted kept target not polled again, got %d polls", client.callCount("b"))
+	}
+	if got := p.Targets(); len(got) != 2 || got[0].Interval != 2*time.Hour {
+		t.Errorf("wanted interval updated and a removed, got %+v", got)
+	}
+}
+
+func TestPollerNonPositiveInterval(t *testing.T) {
+	client := &mockTwitchClient{}
+	p := NewPoller(client, &mockStore{}, WithInterval(0))
+	if p.interval != DefaultInterval {
+		t.Fatalf("wanted default interval %s kept, got %s", DefaultInterval, p.interval)
+	}
+
+	p.SetTargets([]Target{{ChannelID: "a"}})
+	p.Start(context.Background())
+	defer p.Stop()
+
+	// a zero interval would poll a again as soon as it was polled
+	waitFor(t, "target polled", func() bool { return client.callCount("a") == 1 })
+	time.Sleep(30 * time.Millisecond)
+	if n := client.callCount("a"); n != 1 {
+		t.Errorf("wanted one poll, got %d", n)
+	}
+}
+
+func TestPollerBudgetWait(t *testing.T) {
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		state twitch.RateLimitState
+		want  time.Duration
+	}{
+		{name: "above reserve", state: twitch.RateLimitState{Limit: 800, Remaining: 81}},
+		{name: "unknown limit", state: twitch.RateLimitState{}},
+		{name: "at reserve", state: twitch.RateLimitState{Limit: 800, Remaining: 80}, want: time.Second},
+		{name: "refill to reserve", state: twitch.RateLimitState{Limit: 800, Remaining: 0}, want: 6075 * time.Millisecond},
+		{name: "exhausted until reset", state: twitch.RateLimitState{Limit: 800, Remaining: 0, Reset: now.Add(time.Minute)}, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPoller(&mockTwitchClient{}, &mockStore{}, WithBudget(func() twitch.RateLimitState { return tt.state }))
+			p.now = func() time.Time { return now }
+
+			if got := p.budgetWait(); got != tt.want {
+				t.Errorf("wanted %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPollerDefersWithoutBudget(t *testing.T) {
+	client := &mockTwitchClient{}
+	p := NewPoller(client, &mockStore{}, WithBudget(func() twitch.RateLimitState {
+		return twitch.RateLimitState{Limit: 800, Remaining: 10}
+	}))
+	p.SetTargets([]Target{{ChannelID: "a"}})
+	p.Start(context.Background())
+
+	time.Sleep(20 * time.Millisecond)
+	p.Stop()
+	if got := client.callCount("a"); got != 0 {
+		t.Errorf("wanted poll deferred while the budget is below the reserve, got %d polls", got)
+	}
+}
+
+func TestPollerRetriesRateLimitedSooner(t *testing.T) {
+	client := &mockTwitchClient{err: &twitch.APIError{StatusCode: http.StatusTooManyRequests}}
+	store := &mockStore{}
+	p := NewPoller(client, store)
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	p.SetTargets([]Target{{ChannelID: "a"}})
+
+	p.poll(context.Background(), "a")
+	if _, wait := p.nextDue(); wait != minBudgetWait {
+		t.Errorf("wanted rate limited poll retried after %s, got %s", minBudgetWait, wait)
+	}
+	if store.count() != 0 {
+		t.Error("wanted nothing recorded for a failed poll")
+	}
+
+	client.err = errors.New("fetch failed")
+	p.poll(context.Background(), "a")
+	if _, wait := p.nextDue(); wait != DefaultInterval {
+		t.Errorf("wanted failed poll retried at the interval, got %s", wait)
+	}
+}
+
+func TestPollerStopCancelsPoll(t *testing.T) {
+	client := &mockTwitchClient{block: true}
+	p := NewPoller(client, &mockStore{})
+	p.SetTargets([]Target{{ChannelID: "a"}})
+	p.Start(context.Background())
+	p.Start(context.Background()) // no effect while running
+
+	waitFor(t, "poll started", func() bool { return client.callCount("a") == 1 })
+
+	stopped := make(chan struct{})
+	go func() {
+		p.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not cancel the poll in flight")
+	}
+	p.Stop() // no effect once stopped
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/storage"
+	"math"
+	"time"
+)
+
+// defaultFirstDays number of days after publishing views are reported for
+// when the query does not say
+const defaultFirstDays = 7
+
+// ErrNoHistory is returned when no snapshots were recorded for a video.
+var ErrNoHistory = errors.New("no view history recorded")
+
+// HistoryServiceInterface defines the interface for reading video view history.
+type HistoryServiceInterface interface {
+	GetVideoHistory(ctx context.Context, videoID string, query model.HistoryQuery) (model.VideoHistoryResponse, error)
+}
+
+// HistoryService implements HistoryServiceInterface from recorded snapshots
+type HistoryService struct {
+	Store storage.SnapshotStore
+}
+
+// GetVideoHistory returns a video's recorded view counts downsampled to
+// query.Resolution, keeping the last snapshot in each hour or day, with the
+// views gained between points and velocity metrics. Times to reach a share of
+// the current views and views within the first days are interpolated linearly
+// between snapshots, counting from zero views at publishing.
+func (s *HistoryService) GetVideoHistory(ctx context.Context, videoID string, query model.HistoryQuery) (model.VideoHistoryResponse, error) {
+	snapshots, err := s.Store.Snapshots(ctx, videoID, time.Time{}, time.Time{})
+	if err != nil {
+		return model.VideoHistoryResponse{}, err
+	}
+	if len(snapshots) == 0 {
+		return model.VideoHistoryResponse{}, ErrNoHistory
+	}
+
+	video, _, err := s.Store.Video(ctx, videoID)
+	if err != nil {
+		return model.VideoHistoryResponse{}, err
+	}
+	published := video.PublishedAt
+	if published.IsZero() {
+		published = video.CreatedAt
+	}
+
+	if query.Resolution == "" {
+		query.Resolution = model.HistoryResolutionRaw
+	}
+	if query.FirstDays <= 0 {
+		query.FirstDays = defaultFirstDays
+	}
+
+	resp := model.VideoHistoryResponse{
+		VideoID:      videoID,
+		ChannelID:    snapshots[0].ChannelID,
+		Title:        video.Title,
+		PublishedAt:  published,
+		Resolution:   query.Resolution,
+		Snapshots:    len(snapshots),
+		CurrentViews: snapshots[len(snapshots)-1].ViewCount,
+		FirstDays:    query.FirstDays,
+	}
+
+	series := withPublishAnchor(snapshots, published)
+	if !published.IsZero() {
+		resp.HoursTo50Percent = hoursToShare(series, published, 0.5)
+		resp.HoursTo90Percent = hoursToShare(series, published, 0.9)
+		resp.ViewsFirstDays = viewsAt(series, published.AddDate(0, 0, query.FirstDays))
+	}
+
+	resp.Points = historyPoints(downsample(inWindow(snapshots, query.Since, query.Until), query.Resolution.Bucket()))
+	if n := len(resp.Points); n > 1 {
+		first, last := resp.Points[0], resp.Points[n-1]
+		resp.ViewsGained = last.ViewCount - first.ViewCount
+		resp.ViewsPerHour = perHour(resp.ViewsGained, last.At.Sub(first.At))
+		resp.ViewsPerDay = resp.ViewsPerHour * 24
+
+		for _, p := range resp.Points[1:] {
+			if resp.PeakVelocity == nil || p.ViewsPerHour > resp.PeakVelocity.ViewsPerHour {
+				resp.PeakVelocity = &model.Velocity{ViewsPerHour: p.ViewsPerHour, At: p.At}
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+// withPublishAnchor prepends zero views at publishing to snapshots when the
+// video was published before the first of them
+func withPublishAnchor(snapshots []model.Snapshot, published time.Time) []model.Snapshot {
+	if published.IsZero() || !published.Before(snapshots[0].At) {
+		return snapshots
+	}
+	return append([]model.Snapshot{{At: published}}, snapshots...)
+}
+
+// hoursToShare returns the hours from published until the view count first
+// reached share of its latest value, or nil if that happened before series
+// starts or there are no views
+func hoursToShare(series []model.Snapshot, published time.Time, share float64) *float64 {
+	target := share * float64(series[len(series)-1].ViewCount)
+	if target <= 0 {
+		return nil
+	}
+
+	for i, s := range series {
+		if float64(s.ViewCount) < target {
+			continue
+		}
+		if i == 0 {
+			return nil
+		}
+
+		prev := series[i-1]
+		frac := (target - float64(prev.ViewCount)) / float64(s.ViewCount-prev.ViewCount)
+		at := prev.At.Add(time.Duration(frac * float64(s.At.Sub(prev.At))))
+		hours := at.Sub(published).Hours()
+		return &hours
+	}
+	return nil
+}
+
+// viewsAt returns the view count at t interpolated between the series points
+// around it, or nil if t is outside the series
+func viewsAt(series []model.Snapshot, t time.Time) *int {
+	for i, s := range series {
+		if s.At.Before(t) {
+			continue
+		}
+		if s.At.Equal(t) {
+			return &s.ViewCount
+		}
+		if i == 0 {
+			return nil
+		}
+
+		prev := series[i-1]
+		frac := float64(t.Sub(prev.At)) / float64(s.At.Sub(prev.At))
+		views := prev.ViewCount + int(math.Round(frac*float64(s.ViewCount-prev.ViewCount)))
+		return &views
+	}
+	return nil
+}
+
+// inWindow returns the snapshots taken within [since, until), zero bounds being open
+func inWindow(snapshots []model.Snapshot, since, until time.Time) []model.Snapshot {
+	var out []model.Snapshot
+	for _, s := range snapshots {
+		if (since.IsZero() || !s.At.Before(since)) && (until.IsZero() || s.At.Before(until)) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// downsample keeps the last snapshot in each bucket of the given width, all of
+// them if it is zero. Buckets are aligned to UTC hours and days.
+func downsample(snapshots []model.Snapshot, bucket time.Duration) []model.Snapshot {
+	if bucket <= 0 {
+		return snapshots
+	}
+
+	var out []model.Snapshot
+	for _, s := range snapshots {
+		if n := len(out); n > 0 && out[n-1].At.Truncate(bucket).Equal(s.At.Truncate(bucket)) {
+			out[n-1] = s
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// historyPoints converts snapshots to points carrying the views gained since
+// the previous one
+func historyPoints(snapshots []model.Snapshot) []model.HistoryPoint {
+	points := make([]model.HistoryPoint, len(snapshots))
+	for i, s := range snapshots {
+		points[i] = model.HistoryPoint{At: s.At, ViewCount: s.ViewCount}
+		if i > 0 {
+			prev := snapshots[i-1]
+			points[i].ViewsGained = s.ViewCount - prev.ViewCount
+			points[i].ViewsPerHour = perHour(points[i].ViewsGained, s.At.Sub(prev.At))
+		}
+	}
+	return points
+}
+
+// perHour returns views gained over d as a rate per hour, zero for an empty span
+func perHour(views int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(views) / d.Hours()
+}
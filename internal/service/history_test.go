@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/service"
+	"fourthfloor/internal/storage"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newHistoryService returns a HistoryService over a snapshot store holding the
+// given view counts of video "v1", keyed by time since it was published
+func newHistoryService(t *testing.T, published time.Time, views map[time.Duration]int) *service.HistoryService {
+	t.Helper()
+	store, err := storage.Open(filepath.Join(t.TempDir(), "snapshots.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	for after, count := range views {
+		video := model.Video{ID: "v1", UserID: "chan", Title: "VOD", PublishedAt: published, ViewCount: count}
+		if err := store.Record(context.Background(), published.Add(after), video); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	return &service.HistoryService{Store: store}
+}
+
+// ---- Tests ----
+
+func TestGetVideoHistory(t *testing.T) {
+	published := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	s := newHistoryService(t, published, map[time.Duration]int{
+		time.Hour:        100,
+		90 * time.Minute: 150,
+		2 * time.Hour:    400,
+		26 * time.Hour:   800,
+		192 * time.Hour:  1000, // 8 days
+	})
+
+	tests := []struct {
+		name          string
+		query         model.HistoryQuery
+		wantViews     []int
+		wantGained    int
+		wantPeak      float64
+		wantPeakAt    time.Duration
+		wantFirstDays *int
+	}{
+		{
+			name:          "raw",
+			wantViews:     []int{100, 150, 400, 800, 1000},
+			wantGained:    900,
+			wantPeak:      500,
+			wantPeakAt:    2 * time.Hour,
+			wantFirstDays: ptr(971),
+		},
+		{
+			name:          "hourly keeps the last snapshot of each hour",
+			query:         model.HistoryQuery{Resolution: model.HistoryResolutionHour},
+			wantViews:     []int{150, 400, 800, 1000},
+			wantGained:    850,
+			wantPeak:      500,
+			wantPeakAt:    2 * time.Hour,
+			wantFirstDays: ptr(971),
+		},
+		{
+			name:          "daily",
+			query:         model.HistoryQuery{Resolution: model.HistoryResolutionDay, FirstDays: 1},
+			wantViews:     []int{400, 800, 1000},
+			wantGained:    600,
+			wantPeak:      400.0 / 24,
+			wantPeakAt:    26 * time.Hour,
+			wantFirstDays: ptr(767), // 400 + 22/24 of the 400 gained by hour 26
+		},
+		{
+			name:          "window",
+			query:         model.HistoryQuery{Since: published.Add(2 * time.Hour), Until: published.Add(100 * time.Hour), FirstDays: 30},
+			wantViews:     []int{400, 800},
+			wantGained:    400,
+			wantPeak:      400.0 / 24,
+			wantPeakAt:    26 * time.Hour,
+			wantFirstDays: nil, // past the last snapshot
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := s.GetVideoHistory(context.Background(), "v1", tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var views []int
+			for _, p := range resp.Points {
+				views = append(views, p.ViewCount)
+			}
+			if len(views) != len(tt.wantViews) {
+				t.Fatalf("wanted points %v, got %v", tt.wantViews, views)
+			}
+			for i := range views {
+				if views[i] != tt.wantViews[i] {
+					t.Fatalf("wanted points %v, got %v", tt.wantViews, views)
+				}
+			}
+
+			if resp.ViewsGained != tt.wantGained {
+				t.Errorf("wanted %d views gained, got %d", tt.wantGained, resp.ViewsGained)
+			}
+			if resp.PeakVelocity == nil || math.Abs(resp.PeakVelocity.ViewsPerHour-tt.wantPeak) > 1e-9 || !resp.PeakVelocity.At.Equal(published.Add(tt.wantPeakAt)) {
+				t.Errorf("wanted peak %v/h at %s, got %+v", tt.wantPeak, tt.wantPeakAt, resp.PeakVelocity)
+			}
+			if (resp.ViewsFirstDays == nil) != (tt.wantFirstDays == nil) || resp.ViewsFirstDays != nil && *resp.ViewsFirstDays != *tt.wantFirstDays {
+				t.Errorf("wanted views in first days %v, got %v", deref(tt.wantFirstDays), deref(resp.ViewsFirstDays))
+			}
+
+			// whole-life metrics ignore the window and resolution
+			if resp.CurrentViews != 1000 || resp.Snapshots != 5 || resp.ChannelID != "chan" || resp.Title != "VOD" {
+				t.Errorf("unexpected video fields %+v", resp)
+			}
+			if resp.HoursTo50Percent == nil || math.Abs(*resp.HoursTo50Percent-8) > 1e-6 {
+				t.Errorf("wanted 50%% of views after 8h, got %v", deref(resp.HoursTo50Percent))
+			}
+			if resp.HoursTo90Percent == nil || math.Abs(*resp.HoursTo90Percent-109) > 1e-6 {
+				t.Errorf("wanted 90%% of views after 109h, got %v", deref(resp.HoursTo90Percent))
+			}
+		})
+	}
+}
+
+func TestGetVideoHistoryRates(t *testing.T) {
+	published := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	s := newHistoryService(t, published, map[time.Duration]int{
+		24 * time.Hour: 240,
+		48 * time.Hour: 480,
+	})
+
+	resp, err := s.GetVideoHistory(context.Background(), "v1", model.HistoryQuery{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Resolution != model.HistoryResolutionRaw || resp.FirstDays != 7 {
+		t.Errorf("wanted defaults applied, got %s and %d days", resp.Resolution, resp.FirstDays)
+	}
+	if resp.ViewsPerHour != 10 || resp.ViewsPerDay != 240 {
+		t.Errorf("wanted 10/h and 240/day, got %v and %v", resp.ViewsPerHour, resp.ViewsPerDay)
+	}
+	if p := resp.Points[1]; p.ViewsGained != 240 || p.ViewsPerHour != 10 || resp.Points[0].ViewsGained != 0 {
+		t.Errorf("unexpected points %+v", resp.Points)
+	}
+	// interpolated from zero views at publishing
+	if resp.HoursTo50Percent == nil || *resp.HoursTo50Percent != 24 {
+		t.Errorf("wanted 50%% of views after 24h, got %v", deref(resp.HoursTo50Percent))
+	}
+}
+
+func TestGetVideoHistoryUnknownVideo(t *testing.T) {
+	s := newHistoryService(t, time.Now(), nil)
+
+	if _, err := s.GetVideoHistory(context.Background(), "missing", model.HistoryQuery{}); !errors.Is(err, service.ErrNoHistory) {
+		t.Errorf("wanted ErrNoHistory, got %v", err)
+	}
+}
+
+func ptr(n int) *int { return &n }
+
+// deref returns the value of a pointer or nil, for error messages
+func deref[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
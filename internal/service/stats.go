@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fourthfloor/internal/model"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// distribution computes summary statistics over values. An empty input returns
+// the zero Distribution.
+func distribution(values []float64) model.Distribution {
+	if len(values) == 0 {
+		return model.Distribution{}
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	mean := sum / float64(len(sorted))
+
+	var sqDiff float64
+	for _, v := range sorted {
+		sqDiff += (v - mean) * (v - mean)
+	}
+
+	return model.Distribution{
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Mean:   mean,
+		Median: percentile(sorted, 50),
+		P25:    percentile(sorted, 25),
+		P75:    percentile(sorted, 75),
+		P90:    percentile(sorted, 90),
+		P99:    percentile(sorted, 99),
+		StdDev: math.Sqrt(sqDiff / float64(len(sorted))),
+	}
+}
+
+// percentile returns the p-th percentile (0-100) of sorted values, linearly
+// interpolating between the closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// rankVideos returns the top best and bottom worst videos by metric. Top is
+// ordered best first and bottom worst first; ties keep their original order.
+func rankVideos(videos []model.VideoSummary, metric model.RankMetric, top, bottom int) ([]model.VideoSummary, []model.VideoSummary) {
+	ranked := make([]model.VideoSummary, len(videos))
+	copy(ranked, videos)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return metric.Value(ranked[i]) > metric.Value(ranked[j])
+	})
+
+	var best, worst []model.VideoSummary
+	if top > 0 {
+		best = ranked[:min(top, len(ranked))]
+	}
+	for i := len(ranked) - 1; i >= 0 && len(worst) < bottom; i-- {
+		worst = append(worst, ranked[i])
+	}
+
+	return best, worst
+}
+
+// flattenStats returns every numeric field of stats keyed by its JSON name, with
+// nested struct fields joined by a dot. Strings, slices and pointers are skipped.
+func flattenStats(stats model.VideoStatsResponse) map[string]float64 {
+	fields := make(map[string]float64)
+	flattenStruct(reflect.ValueOf(stats), "", fields)
+	return fields
+}
+
+// flattenStruct walks the exported fields of v adding numeric values to fields
+func flattenStruct(v reflect.Value, prefix string, fields map[string]float64) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Int, reflect.Int64:
+			fields[prefix+name] = float64(f.Int())
+		case reflect.Float64:
+			fields[prefix+name] = f.Float()
+		case reflect.Struct:
+			if f.Type() != reflect.TypeOf(time.Time{}) {
+				flattenStruct(f, prefix+name+".", fields)
+			}
+		}
+	}
+}
+
+// statsDeltas computes the absolute and percentage change of every numeric
+// stats field from previous to current
+func statsDeltas(current, previous model.VideoStatsResponse) map[string]model.Delta {
+	cur := flattenStats(current)
+	prev := flattenStats(previous)
+
+	deltas := make(map[string]model.Delta, len(cur))
+	for name, c := range cur {
+		d := model.Delta{Current: c, Previous: prev[name], Absolute: c - prev[name]}
+		if prev[name] != 0 {
+			pct := d.Absolute / math.Abs(prev[name]) * 100
+			d.Percent = &pct
+		}
+		deltas[name] = d
+	}
+
+	return deltas
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fourthfloor/internal/model"
+	"math"
+	"testing"
+)
+
+func TestDistribution(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   map[string]float64
+	}{
+		{
+			name:   "empty",
+			values: nil,
+			want:   map[string]float64{"min": 0, "max": 0, "median": 0, "stddev": 0},
+		},
+		{
+			name:   "single value",
+			values: []float64{42},
+			want:   map[string]float64{"min": 42, "max": 42, "median": 42, "p25": 42, "p99": 42, "stddev": 0},
+		},
+		{
+			name:   "unsorted values",
+			values: []float64{5, 1, 4, 2, 3},
+			want: map[string]float64{
+				"min": 1, "max": 5, "mean": 3, "median": 3,
+				"p25": 2, "p75": 4, "p90": 4.6, "p99": 4.96,
+				"stddev": math.Sqrt(2),
+			},
+		},
+		{
+			name:   "outlier skews mean not median",
+			values: []float64{100, 120, 110, 287856},
+			want:   map[string]float64{"median": 115, "mean": 72046.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := distribution(tt.values)
+			got := map[string]float64{
+				"min": d.Min, "max": d.Max, "mean": d.Mean, "median": d.Median,
+				"p25": d.P25, "p75": d.P75, "p90": d.P90, "p99": d.P99,
+				"stddev": d.StdDev,
+			}
+
+			for key, want := range tt.want {
+				if math.Abs(got[key]-want) > 1e-9 {
+					t.Errorf("wanted %s=%v, got %v", key, want, got[key])
+				}
+			}
+		})
+	}
+}
+
+func TestDistributionDoesNotReorderInput(t *testing.T) {
+	values := []float64{3, 1, 2}
+	distribution(values)
+
+	if values[0] != 3 || values[1] != 1 || values[2] != 2 {
+		t.Errorf("wanted input left unsorted, got %v", values)
+	}
+}
+
+func TestRankVideos(t *testing.T) {
+	videos := []model.VideoSummary{
+		{ID: "a", ViewCount: 100, ViewsPerMinute: 5, DurationMinutes: 20},
+		{ID: "b", ViewCount: 300, ViewsPerMinute: 1, DurationMinutes: 300},
+		{ID: "c", ViewCount: 200, ViewsPerMinute: 10, DurationMinutes: 20},
+		{ID: "d", ViewCount: 50, ViewsPerMinute: 2.5, DurationMinutes: 20},
+	}
+
+	ids := func(vs []model.VideoSummary) string {
+		var s string
+		for _, v := range vs {
+			s += v.ID
+		}
+		return s
+	}
+
+	tests := []struct {
+		name       string
+		metric     model.RankMetric
+		top        int
+		bottom     int
+		wantTop    string
+		wantBottom string
+	}{
+		{name: "default metric is views", top: 2, bottom: 2, wantTop: "bc", wantBottom: "da"},
+		{name: "views per minute", metric: model.RankByViewsPerMinute, top: 1, bottom: 1, wantTop: "c", wantBottom: "b"},
+		{name: "duration ties keep order", metric: model.RankByDuration, top: 2, wantTop: "ba"},
+		{name: "k larger than videos", top: 10, bottom: 10, wantTop: "bcad", wantBottom: "dacb"},
+		{name: "bottom only", bottom: 1, wantBottom: "d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			top, bottom := rankVideos(videos, tt.metric, tt.top, tt.bottom)
+
+			if got := ids(top); got != tt.wantTop {
+				t.Errorf("wanted top %q, got %q", tt.wantTop, got)
+			}
+			if got := ids(bottom); got != tt.wantBottom {
+				t.Errorf("wanted bottom %q, got %q", tt.wantBottom, got)
+			}
+		})
+	}
+
+	if videos[0].ID != "a" {
+		t.Errorf("wanted input order preserved, got %q first", videos[0].ID)
+	}
+}
+
+func TestStatsDeltas(t *testing.T) {
+	current := model.VideoStatsResponse{
+		TotalViews:      300,
+		MostViewedTitle: "ignored",
+		Views:           model.Distribution{Median: 150},
+		Window:          &model.StatsWindow{VideoCount: 2},
+	}
+	previous := model.VideoStatsResponse{
+		TotalViews: 200,
+		Views:      model.Distribution{Median: 0},
+	}
+
+	deltas := statsDeltas(current, previous)
+
+	if d := deltas["total_views"]; d.Absolute != 100 || d.Percent == nil || *d.Percent != 50 {
+		t.Errorf("wanted total_views +100 (+50%%), got %+v", d)
+	}
+	if d := deltas["views_distribution.median"]; d.Absolute != 150 || d.Percent != nil {
+		t.Errorf("wanted views_distribution.median +150 with no percent, got %+v", d)
+	}
+	if _, ok := deltas["duration_minutes_distribution.p99"]; !ok {
+		t.Errorf("wanted every nested distribution field, got %v", deltas)
+	}
+	for _, skipped := range []string{"most_viewed_title", "window", "window.video_count", "videos"} {
+		if _, ok := deltas[skipped]; ok {
+			t.Errorf("wanted %s skipped", skipped)
+		}
+	}
+}
@@ -1,49 +1,458 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fourthfloor/internal/model"
+	"fourthfloor/internal/storage"
 	"fourthfloor/internal/twitch"
+	"log"
+	"sort"
+	"sync"
 	"time"
 )
 
+const (
+	// pageSize number of videos requested per page when scanning a date window
+	pageSize = 100
+
+	// maxWindowVideos caps a date window scan when no limit is given
+	maxWindowVideos = 1000
+
+	// defaultWorkers number of channels fetched concurrently when comparing
+	defaultWorkers = 4
+
+	// DefaultCompareMetric stats field channels are ranked by when none is given
+	DefaultCompareMetric = "total_views"
+
+	// minAgeDays floor applied to video age so videos published within the last
+	// day report their views so far rather than an extrapolated daily rate
+	minAgeDays = 1.0
+)
+
+// ErrNoVideos is returned when a channel has no videos matching the query.
+var ErrNoVideos = errors.New("no videos found")
+
 // VideoServiceInterface defines the interface for fetching video stats.
 type VideoServiceInterface interface {
-	GetVideoStats(channelID string, limit int) (model.VideoStatsResponse, error)
+	GetVideoStats(ctx context.Context, channelID string, query model.StatsQuery) (model.VideoStatsResponse, error)
+	GetVideoStatsByLogin(ctx context.Context, login string, query model.StatsQuery) (model.VideoStatsResponse, error)
+	GetVideoGrowth(ctx context.Context, channelID string, query model.StatsQuery) (model.VideoGrowthResponse, error)
+	CompareChannels(ctx context.Context, channelIDs []string, query model.StatsQuery, metric string) (model.ComparisonResponse, error)
 }
 
 // VideoService implements VideoServiceInterface
 type VideoService struct {
 	TwitchClient twitch.TwitchAPIClientInterface
+
+	// Now returns the current time used for video ages, defaults to time.Now
+	Now func() time.Time
+
+	// Workers bounds concurrent channel fetches in CompareChannels, defaults to 4
+	Workers int
+
+	// Snapshots, when set, provides the recorded view counts that views within
+	// the first days after publishing are estimated from
+	Snapshots storage.SnapshotStore
 }
 
-// GetVideoStats fetches videos from TwitchClient and computes stats.
-func (s *VideoService) GetVideoStats(channelID string, limit int) (model.VideoStatsResponse, error) {
-	videos, err := s.TwitchClient.FetchVideos(channelID, limit)
+// now returns the current time from Now if set
+func (s *VideoService) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// GetVideoStats fetches videos matching query from TwitchClient and computes stats.
+// Without a date window the last query.Limit videos are aggregated; with one, pages
+// are fetched until the lower bound is passed and only videos created inside the
+// window are aggregated, with query.Limit as an upper cap.
+func (s *VideoService) GetVideoStats(ctx context.Context, channelID string, query model.StatsQuery) (model.VideoStatsResponse, error) {
+	if !query.Windowed() {
+		videos, err := s.TwitchClient.FetchVideos(ctx, channelID, query.Limit, query.Filter)
+		if err != nil {
+			return model.VideoStatsResponse{}, err
+		}
+		return s.buildStats(ctx, videos, query)
+	}
+
+	videos, truncated, err := s.fetchWindow(ctx, channelID, query)
 	if err != nil {
 		return model.VideoStatsResponse{}, err
 	}
 
+	stats, err := s.buildStats(ctx, videos, query)
+	if err != nil {
+		return model.VideoStatsResponse{}, err
+	}
+
+	window := &model.StatsWindow{
+		Since:      query.Since,
+		Until:      query.Until,
+		VideoCount: len(videos),
+		Truncated:  truncated,
+	}
+
+	// fill open bounds from the videos aggregated
+	for _, v := range videos {
+		if query.Since.IsZero() && (window.Since.IsZero() || v.CreatedAt.Before(window.Since)) {
+			window.Since = v.CreatedAt
+		}
+		if query.Until.IsZero() && v.CreatedAt.After(window.Until) {
+			window.Until = v.CreatedAt
+		}
+	}
+	stats.Window = window
+
+	return stats, nil
+}
+
+// GetVideoStatsByLogin resolves a login name to its channel and computes stats as
+// GetVideoStats, including the user profile in the response.
+func (s *VideoService) GetVideoStatsByLogin(ctx context.Context, login string, query model.StatsQuery) (model.VideoStatsResponse, error) {
+	user, err := s.TwitchClient.FetchUserByLogin(ctx, login)
+	if err != nil {
+		return model.VideoStatsResponse{}, err
+	}
+
+	stats, err := s.GetVideoStats(ctx, user.ID, query)
+	if err != nil {
+		return model.VideoStatsResponse{}, err
+	}
+
+	stats.User = &user
+	return stats, nil
+}
+
+// GetVideoGrowth computes stats for the period described by query and for the
+// preceding equivalent period, returning both with the change between them.
+// Without a date window the previous period is the query.Limit videos before the
+// latest query.Limit; with one it is the window of equal length ending at
+// query.Since, with query.Until defaulting to now, and each window is capped at
+// query.Limit videos on its own. An empty previous period leaves Previous
+// zero-valued, and Deltas are left out unless both periods are complete: the
+// previous one holding query.Limit videos without a window, and neither window
+// truncated with one, since they would not compare like with like.
+func (s *VideoService) GetVideoGrowth(ctx context.Context, channelID string, query model.StatsQuery) (model.VideoGrowthResponse, error) {
+	var current, previous []model.Video
+	var currentWindow, previousWindow *model.StatsWindow
+
+	if !query.Windowed() {
+		videos, err := s.TwitchClient.FetchVideos(ctx, channelID, 2*query.Limit, query.Filter)
+		if err != nil {
+			return model.VideoGrowthResponse{}, err
+		}
+		current = videos[:min(query.Limit, len(videos))]
+		previous = videos[len(current):]
+	} else {
+		if query.Since.IsZero() {
+			return model.VideoGrowthResponse{}, errors.New("growth window requires since")
+		}
+		if query.Until.IsZero() {
+			query.Until = s.now()
+		}
+
+		previousQuery := query
+		previousQuery.Since, previousQuery.Until = query.Since.Add(-query.Until.Sub(query.Since)), query.Since
+
+		var currentTruncated, previousTruncated bool
+		var err error
+		if current, currentTruncated, err = s.fetchWindow(ctx, channelID, query); err != nil {
+			return model.VideoGrowthResponse{}, err
+		}
+		if previous, previousTruncated, err = s.fetchWindow(ctx, channelID, previousQuery); err != nil {
+			return model.VideoGrowthResponse{}, err
+		}
+
+		currentWindow = &model.StatsWindow{Since: query.Since, Until: query.Until, VideoCount: len(current), Truncated: currentTruncated}
+		previousWindow = &model.StatsWindow{Since: previousQuery.Since, Until: previousQuery.Until, VideoCount: len(previous), Truncated: previousTruncated}
+	}
+
+	var growth model.VideoGrowthResponse
+	var err error
+
+	if growth.Current, err = s.buildStats(ctx, current, query); err != nil {
+		return model.VideoGrowthResponse{}, err
+	}
+	if len(previous) > 0 {
+		if growth.Previous, err = s.buildStats(ctx, previous, query); err != nil {
+			return model.VideoGrowthResponse{}, err
+		}
+	}
+
+	growth.Current.Window = currentWindow
+	growth.Previous.Window = previousWindow
+	complete := len(previous) > 0
+	if query.Windowed() {
+		complete = complete && !currentWindow.Truncated && !previousWindow.Truncated
+	} else {
+		complete = complete && len(previous) == query.Limit
+	}
+	if complete {
+		growth.Deltas = statsDeltas(growth.Current, growth.Previous)
+	}
+
+	return growth, nil
+}
+
+// CompareChannels computes stats for each channel concurrently, with at most
+// Workers fetches in flight, and ranks the channels by metric (a stats field
+// name as accepted by IsStatsMetric). Channels that fail are reported with their
+// error and left out of the ranking; if every channel fails the first error is
+// returned. No further channels are fetched once ctx is done.
+func (s *VideoService) CompareChannels(ctx context.Context, channelIDs []string, query model.StatsQuery, metric string) (model.ComparisonResponse, error) {
+	if metric == "" {
+		metric = DefaultCompareMetric
+	}
+	if !IsStatsMetric(metric) {
+		return model.ComparisonResponse{}, errors.New("unknown metric " + metric)
+	}
+
+	workers := s.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	results := make([]model.ChannelStats, len(channelIDs))
+	errs := make([]error, len(channelIDs))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(channelIDs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].ChannelID = channelIDs[i]
+				stats, err := s.GetVideoStats(ctx, channelIDs[i], query)
+				if err != nil {
+					results[i].Error = err.Error()
+					errs[i] = err
+					continue
+				}
+				results[i].Stats = &stats
+			}
+		}()
+	}
+	for i := range channelIDs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// the caller has gone or timed out, partial results are of no use
+	if err := ctx.Err(); err != nil {
+		return model.ComparisonResponse{}, err
+	}
+
+	var ranking []model.RankingEntry
+	for _, r := range results {
+		if r.Stats != nil {
+			ranking = append(ranking, model.RankingEntry{ChannelID: r.ChannelID, Value: flattenStats(*r.Stats)[metric]})
+		}
+	}
+	if len(ranking) == 0 && len(errs) > 0 {
+		return model.ComparisonResponse{}, errs[0]
+	}
+
+	sort.SliceStable(ranking, func(i, j int) bool { return ranking[i].Value > ranking[j].Value })
+	for i := range ranking {
+		ranking[i].Rank = i + 1
+	}
+
+	return model.ComparisonResponse{Metric: metric, Channels: results, Ranking: ranking}, nil
+}
+
+// IsStatsMetric reports whether name is a numeric stats field that channels can
+// be ranked by, using the JSON field names of VideoStatsResponse with nested
+// fields joined by a dot (e.g. "views_distribution.median").
+func IsStatsMetric(name string) bool {
+	_, ok := flattenStats(model.VideoStatsResponse{})[name]
+	return ok
+}
+
+// fetchWindow pages through a channel's videos collecting those created within the
+// query window. When videos are time sorted paging stops once a video older than
+// Since is seen; otherwise the channel is scanned until the cap is reached. The
+// returned bool reports whether the cap cut the scan short.
+func (s *VideoService) fetchWindow(ctx context.Context, channelID string, query model.StatsQuery) ([]model.Video, bool, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = maxWindowVideos
+	}
+
+	sorted := query.Filter.Sort == "" || query.Filter.Sort == model.VideoSortTime
+
+	var videos []model.Video
+	cursor := ""
+
+	for {
+		page, err := s.TwitchClient.FetchVideoPage(ctx, channelID, pageSize, query.Filter, cursor)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, v := range page.Data {
+			if !query.Until.IsZero() && !v.CreatedAt.Before(query.Until) {
+				continue
+			}
+			if !query.Since.IsZero() && v.CreatedAt.Before(query.Since) {
+				if sorted {
+					return videos, false, nil
+				}
+				continue
+			}
+
+			videos = append(videos, v)
+			if len(videos) == limit {
+				return videos, true, nil
+			}
+		}
+
+		cursor = page.Pagination.Cursor
+		if cursor == "" || len(page.Data) == 0 {
+			return videos, false, nil
+		}
+	}
+}
+
+// buildStats summarizes videos and computes stats, attaching the per-video
+// summaries and ranked lists when the query asks for them.
+func (s *VideoService) buildStats(ctx context.Context, videos []model.Video, query model.StatsQuery) (model.VideoStatsResponse, error) {
+	summaries := summarizeVideos(videos, s.now())
+
+	stats, err := computeStats(summaries)
+	if err != nil {
+		return model.VideoStatsResponse{}, err
+	}
+
+	if s.Snapshots != nil {
+		stats.FirstDays = query.FirstDays
+		if stats.FirstDays <= 0 {
+			stats.FirstDays = defaultFirstDays
+		}
+		stats.ViewsFirstDays = s.viewsFirstDays(ctx, summaries, stats.FirstDays)
+	}
+
+	if query.Top > 0 || query.Bottom > 0 {
+		stats.Top, stats.Bottom = rankVideos(summaries, query.RankBy, query.Top, query.Bottom)
+	}
+	if query.IncludeVideos {
+		stats.Videos = summaries
+	}
+	return stats, nil
+}
+
+// viewsFirstDays sets each summary's views within the first days after
+// publishing, interpolated from its snapshots like the history endpoint, and
+// returns their distribution, or nil if no video's snapshots cover that point.
+// A failing store is logged rather than failing the stats.
+func (s *VideoService) viewsFirstDays(ctx context.Context, summaries []model.VideoSummary, days int) *model.Distribution {
+	var values []float64
+	for i := range summaries {
+		v := &summaries[i]
+		if v.PublishedAt.IsZero() {
+			continue
+		}
+
+		snapshots, err := s.Snapshots.Snapshots(ctx, v.ID, time.Time{}, time.Time{})
+		if err != nil {
+			log.Printf("read snapshots of video %s: %v", v.ID, err)
+			return nil
+		}
+		if len(snapshots) == 0 {
+			continue
+		}
+
+		series := withPublishAnchor(snapshots, v.PublishedAt)
+		if v.ViewsFirstDays = viewsAt(series, v.PublishedAt.AddDate(0, 0, days)); v.ViewsFirstDays != nil {
+			values = append(values, float64(*v.ViewsFirstDays))
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	d := distribution(values)
+	return &d
+}
+
+// summarizeVideos derives per-video metrics, measuring age at now from publish
+// time (falling back to creation time for unpublished videos). Videos with an
+// unparseable duration report zero duration minutes.
+func summarizeVideos(videos []model.Video, now time.Time) []model.VideoSummary {
+	summaries := make([]model.VideoSummary, 0, len(videos))
+
+	for _, v := range videos {
+		summary := model.VideoSummary{
+			ID:          v.ID,
+			Title:       v.Title,
+			URL:         v.URL,
+			PublishedAt: v.PublishedAt,
+			ViewCount:   v.ViewCount,
+		}
+		if summary.PublishedAt.IsZero() {
+			summary.PublishedAt = v.CreatedAt
+		}
+
+		// parse duration
+		if dur, err := time.ParseDuration(v.Duration); err == nil && dur > 0 {
+			summary.DurationMinutes = dur.Minutes()
+			summary.ViewsPerMinute = float64(v.ViewCount) / summary.DurationMinutes
+		}
+
+		if !summary.PublishedAt.IsZero() {
+			summary.AgeDays = now.Sub(summary.PublishedAt).Hours() / 24
+			summary.ViewsPerDay = float64(v.ViewCount) / max(summary.AgeDays, minAgeDays)
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries
+}
+
+// computeStats aggregates stats over video summaries.
+func computeStats(videos []model.VideoSummary) (model.VideoStatsResponse, error) {
 	if len(videos) == 0 {
-		return model.VideoStatsResponse{}, errors.New("no videos found")
+		return model.VideoStatsResponse{}, ErrNoVideos
 	}
 
 	var totalViews int
 	var totalDur float64
-	var mostViewed model.Video
+	var mostViewed model.VideoSummary
+	minViewed := videos[0]
+
+	views := make([]float64, 0, len(videos))
+	durations := make([]float64, 0, len(videos))
+	viewsPerDay := make([]float64, 0, len(videos))
 
 	for _, v := range videos {
 		totalViews += v.ViewCount
+		views = append(views, float64(v.ViewCount))
 
-		// parse duration
-		if dur, err := time.ParseDuration(v.Duration); err == nil {
-			totalDur += dur.Minutes()
+		// only videos with a valid duration count towards duration stats
+		if v.DurationMinutes > 0 {
+			totalDur += v.DurationMinutes
+			durations = append(durations, v.DurationMinutes)
+		}
+
+		// only videos with a known publish time have an age
+		if !v.PublishedAt.IsZero() {
+			viewsPerDay = append(viewsPerDay, v.ViewsPerDay)
 		}
 
-		// track most viewed video
+		// track most and least viewed videos
 		if v.ViewCount > mostViewed.ViewCount {
 			mostViewed = v
 		}
+		if v.ViewCount < minViewed.ViewCount {
+			minViewed = v
+		}
 	}
 
 	avgViews := float64(totalViews) / float64(len(videos))
@@ -60,5 +469,10 @@ func (s *VideoService) GetVideoStats(channelID string, limit int) (model.VideoSt
 		AvgViewsPerMinute:    avgViewsPerMinute,
 		MostViewedTitle:      mostViewed.Title,
 		MostViewedViewCount:  mostViewed.ViewCount,
+		MinViewedTitle:       minViewed.Title,
+		MinViewedViewCount:   minViewed.ViewCount,
+		Views:                distribution(views),
+		DurationMinutes:      distribution(durations),
+		ViewsPerDay:          distribution(viewsPerDay),
 	}, nil
 }
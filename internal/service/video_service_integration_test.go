@@ -3,7 +3,9 @@
 package service_test
 
 import (
+	"context"
 	"fourthfloor/internal/config"
+	"fourthfloor/internal/model"
 	"fourthfloor/internal/service"
 	"fourthfloor/internal/twitch"
 	"testing"
@@ -17,7 +19,7 @@ func TestVideoService_Integration(t *testing.T) {
 
 	videoService := &service.VideoService{TwitchClient: client}
 
-	stats, err := videoService.GetVideoStats(cfg.ChannelID, 2)
+	stats, err := videoService.GetVideoStats(context.Background(), cfg.FirstChannelID(), model.StatsQuery{Limit: 2})
 	if err != nil {
 		t.Fatalf("FetchVideos failed: %v", err)
 	}
@@ -1,26 +1,99 @@
 package service_test
 
 import (
+	"context"
 	"errors"
 	"fourthfloor/internal/model"
 	"fourthfloor/internal/service"
+	"fourthfloor/internal/storage"
+	"fourthfloor/internal/twitch"
+	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
 
 // ---- Mocks ----
 
+// mockChannelClient implements TwitchAPIClientInterface serving different videos
+// per channel and tracking concurrent fetches.
+type mockChannelClient struct {
+	videos map[string][]model.Video
+	errs   map[string]error
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (m *mockChannelClient) FetchVideos(ctx context.Context, channelID string, limit int, filter model.VideoFilter) ([]model.Video, error) {
+	m.mu.Lock()
+	m.inFlight++
+	m.maxInFlight = max(m.maxInFlight, m.inFlight)
+	m.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	m.mu.Lock()
+	m.inFlight--
+	m.mu.Unlock()
+
+	return m.videos[channelID], m.errs[channelID]
+}
+
+func (m *mockChannelClient) FetchUserByLogin(ctx context.Context, login string) (model.User, error) {
+	return model.User{}, twitch.ErrChannelNotFound
+}
+
+func (m *mockChannelClient) FetchVideoPage(ctx context.Context, channelID string, first int, filter model.VideoFilter, cursor string) (model.VideoResponse, error) {
+	videos, err := m.FetchVideos(ctx, channelID, first, filter)
+	return model.VideoResponse{Data: videos}, err
+}
+
 // mockTwitchClient implements TwitchAPIClientInterface for testing.
 type mockTwitchClient struct {
 	videos []model.Video
+	users  map[string]model.User
 	err    error
+
+	pages     int
+	channelID string
 }
 
 // FetchVideos mock return from FetchVideos function (client.go)
-func (m *mockTwitchClient) FetchVideos(channelID string, limit int) ([]model.Video, error) {
+func (m *mockTwitchClient) FetchVideos(ctx context.Context, channelID string, limit int, filter model.VideoFilter) ([]model.Video, error) {
+	m.channelID = channelID
 	return m.videos, m.err
 }
 
+// FetchUserByLogin mock return from FetchUserByLogin function (users.go)
+func (m *mockTwitchClient) FetchUserByLogin(ctx context.Context, login string) (model.User, error) {
+	user, ok := m.users[login]
+	if !ok {
+		return model.User{}, twitch.ErrChannelNotFound
+	}
+	return user, nil
+}
+
+// FetchVideoPage mock return from FetchVideoPage function (client.go), paging
+// through videos using the offset as cursor
+func (m *mockTwitchClient) FetchVideoPage(ctx context.Context, channelID string, first int, filter model.VideoFilter, cursor string) (model.VideoResponse, error) {
+	if m.err != nil {
+		return model.VideoResponse{}, m.err
+	}
+
+	m.pages++
+	offset, _ := strconv.Atoi(cursor)
+	end := min(offset+first, len(m.videos))
+
+	resp := model.VideoResponse{Data: m.videos[offset:end]}
+	if end < len(m.videos) {
+		resp.Pagination.Cursor = strconv.Itoa(end)
+	}
+	return resp, nil
+}
+
 // ---- Tests ----
 
 func TestVideoService_GetVideoStats(t *testing.T) {
@@ -31,6 +104,7 @@ func TestVideoService_GetVideoStats(t *testing.T) {
 		expectedErr   bool
 		expectedTotal int
 		expectedMost  string
+		expectedMin   string
 	}{
 		{
 			name: "success case",
@@ -41,6 +115,7 @@ func TestVideoService_GetVideoStats(t *testing.T) {
 			expectedErr:   false,
 			expectedTotal: 300,
 			expectedMost:  "Vid2",
+			expectedMin:   "Vid1",
 		},
 		{
 			name:          "no videos returned",
@@ -61,6 +136,7 @@ func TestVideoService_GetVideoStats(t *testing.T) {
 			expectedErr:   false,
 			expectedTotal: 50,
 			expectedMost:  "BadDuration",
+			expectedMin:   "BadDuration",
 		},
 	}
 
@@ -75,7 +151,7 @@ func TestVideoService_GetVideoStats(t *testing.T) {
 				TwitchClient: mockClient,
 			}
 
-			stats, err := svc.GetVideoStats("channel1", 10)
+			stats, err := svc.GetVideoStats(context.Background(), "channel1", model.StatsQuery{Limit: 10})
 
 			if tt.expectedErr && err == nil {
 				t.Errorf("expected error, got nil")
@@ -91,6 +167,12 @@ func TestVideoService_GetVideoStats(t *testing.T) {
 				if stats.MostViewedTitle != tt.expectedMost {
 					t.Errorf("expected most viewed %q, got %q", tt.expectedMost, stats.MostViewedTitle)
 				}
+				if stats.MinViewedTitle != tt.expectedMin {
+					t.Errorf("expected min viewed %q, got %q", tt.expectedMin, stats.MinViewedTitle)
+				}
+				if stats.Views.Median <= 0 || stats.Views.Max != float64(stats.MostViewedViewCount) {
+					t.Errorf("wanted views distribution to match videos, got %+v", stats.Views)
+				}
 
 				// only check total duration if at least one valid duration exists
 				hasValidDuration := false
@@ -104,6 +186,9 @@ func TestVideoService_GetVideoStats(t *testing.T) {
 				if hasValidDuration && stats.TotalDurationMinutes <= 0 {
 					t.Errorf("wanted total duration > 0, got %f", stats.TotalDurationMinutes)
 				}
+				if hasValidDuration != (stats.DurationMinutes.Median > 0) {
+					t.Errorf("wanted duration distribution only from valid durations, got %+v", stats.DurationMinutes)
+				}
 
 				if stats.TotalDurationMinutes > 0 && stats.AvgViewsPerMinute <= 0 {
 					t.Errorf("wanted AvgViewsPerMinute > 0, got %f", stats.AvgViewsPerMinute)
@@ -116,3 +201,465 @@ func TestVideoService_GetVideoStats(t *testing.T) {
 		})
 	}
 }
+
+func TestVideoService_GetVideoStatsWindow(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 9, d, 12, 0, 0, 0, time.UTC) }
+
+	// 300 videos, newest first, one per hour going back from Sep 30
+	var videos []model.Video
+	for i := 0; i < 300; i++ {
+		videos = append(videos, model.Video{
+			Title:     "Vid" + strconv.Itoa(i),
+			ViewCount: 10,
+			Duration:  "1h0m0s",
+			CreatedAt: day(30).Add(-time.Duration(i) * time.Hour),
+		})
+	}
+
+	tests := []struct {
+		name          string
+		query         model.StatsQuery
+		expectedCount int
+		expectedPages int
+		expectedSince time.Time
+		expectedUntil time.Time
+		truncated     bool
+		expectedErr   bool
+	}{
+		{
+			name:          "closed window stops after lower bound",
+			query:         model.StatsQuery{Since: day(29), Until: day(30)},
+			expectedCount: 24,
+			expectedPages: 1,
+			expectedSince: day(29),
+			expectedUntil: day(30),
+		},
+		{
+			name:          "window spanning pages",
+			query:         model.StatsQuery{Since: day(25)},
+			expectedCount: 121,
+			expectedPages: 2,
+			expectedSince: day(25),
+			expectedUntil: day(30),
+		},
+		{
+			name:          "window capped by limit",
+			query:         model.StatsQuery{Limit: 10, Since: day(1)},
+			expectedCount: 10,
+			expectedPages: 1,
+			expectedSince: day(1),
+			expectedUntil: day(30),
+			truncated:     true,
+		},
+		{
+			name:          "open lower bound scans channel",
+			query:         model.StatsQuery{Until: day(29)},
+			expectedCount: 275,
+			expectedPages: 3,
+			expectedSince: videos[299].CreatedAt,
+			expectedUntil: day(29),
+		},
+		{
+			name:        "empty window",
+			query:       model.StatsQuery{Since: day(1), Until: day(2)},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockTwitchClient{videos: videos}
+			svc := &service.VideoService{TwitchClient: mockClient}
+
+			stats, err := svc.GetVideoStats(context.Background(), "channel1", tt.query)
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if stats.Window == nil {
+				t.Fatalf("expected window in response")
+			}
+			if stats.Window.VideoCount != tt.expectedCount || stats.TotalViews != tt.expectedCount*10 {
+				t.Errorf("expected %d videos, got %d (total views %d)", tt.expectedCount, stats.Window.VideoCount, stats.TotalViews)
+			}
+			if mockClient.pages != tt.expectedPages {
+				t.Errorf("expected %d pages fetched, got %d", tt.expectedPages, mockClient.pages)
+			}
+			if !stats.Window.Since.Equal(tt.expectedSince) || !stats.Window.Until.Equal(tt.expectedUntil) {
+				t.Errorf("expected window [%v, %v), got [%v, %v)", tt.expectedSince, tt.expectedUntil, stats.Window.Since, stats.Window.Until)
+			}
+			if stats.Window.Truncated != tt.truncated {
+				t.Errorf("expected truncated=%v, got %v", tt.truncated, stats.Window.Truncated)
+			}
+		})
+	}
+}
+
+func TestVideoService_GetVideoStatsViewsPerDay(t *testing.T) {
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	videos := []model.Video{
+		// six months old, 18000 views -> ~100/day
+		{ID: "old", Title: "Old", ViewCount: 18000, Duration: "1h0m0s", PublishedAt: now.AddDate(0, 0, -180)},
+		// ten days old, 5000 views -> 500/day
+		{ID: "recent", Title: "Recent", ViewCount: 5000, Duration: "1h0m0s", PublishedAt: now.AddDate(0, 0, -10)},
+		// published an hour ago, age floored to one day
+		{ID: "new", Title: "New", ViewCount: 300, Duration: "30m0s", PublishedAt: now.Add(-time.Hour)},
+		// unpublished, age taken from creation time
+		{ID: "created", Title: "Created", ViewCount: 200, Duration: "1h0m0s", CreatedAt: now.AddDate(0, 0, -2)},
+	}
+
+	svc := &service.VideoService{
+		TwitchClient: &mockTwitchClient{videos: videos},
+		Now:          func() time.Time { return now },
+	}
+
+	stats, err := svc.GetVideoStats(context.Background(), "channel1", model.StatsQuery{Limit: 4, IncludeVideos: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stats.Videos) != len(videos) {
+		t.Fatalf("expected %d video summaries, got %d", len(videos), len(stats.Videos))
+	}
+
+	wantPerDay := map[string]float64{"old": 100, "recent": 500, "new": 300, "created": 100}
+	for _, v := range stats.Videos {
+		if v.ViewsPerDay != wantPerDay[v.ID] {
+			t.Errorf("expected %s views/day %v, got %v", v.ID, wantPerDay[v.ID], v.ViewsPerDay)
+		}
+	}
+
+	if stats.ViewsPerDay.Median != 200 {
+		t.Errorf("expected median views/day 200, got %v", stats.ViewsPerDay.Median)
+	}
+	if stats.Videos[2].ViewsPerMinute != 10 {
+		t.Errorf("expected 10 views/minute for New, got %v", stats.Videos[2].ViewsPerMinute)
+	}
+
+	// summaries are opt-in
+	stats, err = svc.GetVideoStats(context.Background(), "channel1", model.StatsQuery{Limit: 4})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Videos != nil {
+		t.Errorf("expected no video summaries without IncludeVideos, got %d", len(stats.Videos))
+	}
+}
+
+func TestVideoService_GetVideoStatsTopBottom(t *testing.T) {
+	videos := []model.Video{
+		{ID: "1", Title: "Vid1", ViewCount: 100, Duration: "10m0s", URL: "https://www.twitch.tv/videos/1"},
+		{ID: "2", Title: "Vid2", ViewCount: 400, Duration: "20m0s", URL: "https://www.twitch.tv/videos/2"},
+		{ID: "3", Title: "Vid3", ViewCount: 300, Duration: "60m0s", URL: "https://www.twitch.tv/videos/3"},
+	}
+
+	svc := &service.VideoService{TwitchClient: &mockTwitchClient{videos: videos}}
+
+	stats, err := svc.GetVideoStats(context.Background(), "channel1", model.StatsQuery{Limit: 3, Top: 2, Bottom: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(stats.Top) != 2 || stats.Top[0].ID != "2" || stats.Top[1].ID != "3" {
+		t.Errorf("expected top [2 3], got %+v", stats.Top)
+	}
+	if len(stats.Bottom) != 1 || stats.Bottom[0].ID != "1" {
+		t.Errorf("expected bottom [1], got %+v", stats.Bottom)
+	}
+	if stats.Top[0].URL != "https://www.twitch.tv/videos/2" || stats.Top[0].ViewsPerMinute != 20 {
+		t.Errorf("expected ranked entry to carry url and views per minute, got %+v", stats.Top[0])
+	}
+
+	stats, err = svc.GetVideoStats(context.Background(), "channel1", model.StatsQuery{Limit: 3, Top: 1, RankBy: model.RankByViewsPerMinute})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats.Top) != 1 || stats.Top[0].ID != "2" || stats.Bottom != nil {
+		t.Errorf("expected top [2] by views per minute and no bottom, got top=%+v bottom=%+v", stats.Top, stats.Bottom)
+	}
+}
+
+func TestVideoService_GetVideoStatsViewsFirstDays(t *testing.T) {
+	published := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	videos := []model.Video{
+		{ID: "v1", ViewCount: 800, PublishedAt: published},
+		{ID: "v2", ViewCount: 500, PublishedAt: published},
+		{ID: "v3", ViewCount: 300, PublishedAt: published},
+	}
+
+	store, err := storage.Open(filepath.Join(t.TempDir(), "snapshots.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	// v1 is covered past day 7, v2 only up to day 2, v3 has no snapshots
+	for _, snap := range []struct {
+		after time.Duration
+		video model.Video
+	}{
+		{day, model.Video{ID: "v1", ViewCount: 100}},
+		{8 * day, model.Video{ID: "v1", ViewCount: 800}},
+		{2 * day, model.Video{ID: "v2", ViewCount: 500}},
+	} {
+		if err := store.Record(ctx, published.Add(snap.after), snap.video); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	now := func() time.Time { return published.Add(10 * day) }
+	svc := &service.VideoService{TwitchClient: &mockTwitchClient{videos: videos}, Snapshots: store, Now: now}
+
+	stats, err := svc.GetVideoStats(ctx, "channel1", model.StatsQuery{Limit: 3, IncludeVideos: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 100 + 6/7 of the 700 gained between days 1 and 8
+	if stats.FirstDays != 7 || stats.ViewsFirstDays == nil || stats.ViewsFirstDays.Median != 700 {
+		t.Errorf("expected 700 views within 7 days, got %d days %+v", stats.FirstDays, stats.ViewsFirstDays)
+	}
+	if v := stats.Videos[0].ViewsFirstDays; v == nil || *v != 700 {
+		t.Errorf("expected v1 views_first_days 700, got %v", v)
+	}
+	if stats.Videos[1].ViewsFirstDays != nil || stats.Videos[2].ViewsFirstDays != nil {
+		t.Errorf("expected no estimate without covering snapshots, got %+v", stats.Videos[1:])
+	}
+
+	// v2's snapshots cover its first day
+	stats, _ = svc.GetVideoStats(ctx, "channel1", model.StatsQuery{Limit: 3, FirstDays: 1})
+	if stats.FirstDays != 1 || stats.ViewsFirstDays == nil || stats.ViewsFirstDays.Min != 100 || stats.ViewsFirstDays.Max != 250 {
+		t.Errorf("expected first day views 100 and 250, got %+v", stats.ViewsFirstDays)
+	}
+
+	// without a snapshot store nothing is reported
+	svc.Snapshots = nil
+	if stats, _ := svc.GetVideoStats(ctx, "channel1", model.StatsQuery{Limit: 3}); stats.FirstDays != 0 || stats.ViewsFirstDays != nil {
+		t.Errorf("expected no first days without snapshots, got %d %+v", stats.FirstDays, stats.ViewsFirstDays)
+	}
+}
+
+func TestVideoService_GetVideoGrowth(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 9, d, 12, 0, 0, 0, time.UTC) }
+
+	// one video per day, newest first, views growing by 10 each day
+	var videos []model.Video
+	for d := 30; d >= 1; d-- {
+		videos = append(videos, model.Video{
+			ID:        strconv.Itoa(d),
+			ViewCount: d * 10,
+			Duration:  "1h0m0s",
+			CreatedAt: day(d),
+		})
+	}
+
+	t.Run("last n videos", func(t *testing.T) {
+		svc := &service.VideoService{TwitchClient: &mockTwitchClient{videos: videos[:6]}}
+
+		growth, err := svc.GetVideoGrowth(context.Background(), "channel1", model.StatsQuery{Limit: 3})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// current 300+290+280, previous 270+260+250
+		if growth.Current.TotalViews != 870 || growth.Previous.TotalViews != 780 {
+			t.Errorf("expected totals 870/780, got %d/%d", growth.Current.TotalViews, growth.Previous.TotalViews)
+		}
+		d := growth.Deltas["total_views"]
+		if d.Absolute != 90 || d.Percent == nil || *d.Percent < 11.53 || *d.Percent > 11.54 {
+			t.Errorf("expected total_views +90 (~11.5%%), got %+v", d)
+		}
+	})
+
+	t.Run("date window", func(t *testing.T) {
+		svc := &service.VideoService{TwitchClient: &mockTwitchClient{videos: videos}}
+
+		growth, err := svc.GetVideoGrowth(context.Background(), "channel1", model.StatsQuery{Since: day(24), Until: day(28)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// current days 24-27, previous days 20-23
+		if growth.Current.Window.VideoCount != 4 || growth.Previous.Window.VideoCount != 4 {
+			t.Fatalf("expected 4 videos per window, got %+v / %+v", growth.Current.Window, growth.Previous.Window)
+		}
+		if !growth.Previous.Window.Since.Equal(day(20)) || !growth.Previous.Window.Until.Equal(day(24)) {
+			t.Errorf("expected previous window [day 20, day 24), got %+v", growth.Previous.Window)
+		}
+		if d := growth.Deltas["total_views"]; d.Absolute != 160 {
+			t.Errorf("expected total_views +160, got %+v", d)
+		}
+	})
+
+	t.Run("partial previous period", func(t *testing.T) {
+		svc := &service.VideoService{TwitchClient: &mockTwitchClient{videos: videos[:5]}}
+
+		growth, err := svc.GetVideoGrowth(context.Background(), "channel1", model.StatsQuery{Limit: 4})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// one video precedes the latest four
+		if growth.Previous.TotalViews != 260 || growth.Deltas != nil {
+			t.Errorf("expected previous total 260 and no deltas, got %d / %+v", growth.Previous.TotalViews, growth.Deltas)
+		}
+	})
+
+	t.Run("current window truncated", func(t *testing.T) {
+		// no videos on days 14-16
+		var sparse []model.Video
+		for _, v := range videos {
+			if d := v.CreatedAt.Day(); d < 14 || d > 16 {
+				sparse = append(sparse, v)
+			}
+		}
+		svc := &service.VideoService{TwitchClient: &mockTwitchClient{videos: sparse}}
+
+		// current days 21-28 capped at 6, previous days 13-20 hold 5
+		growth, err := svc.GetVideoGrowth(context.Background(), "channel1", model.StatsQuery{Limit: 6, Since: day(21), Until: day(29)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !growth.Current.Window.Truncated || growth.Previous.Window.Truncated {
+			t.Fatalf("expected only current window truncated, got %+v / %+v", growth.Current.Window, growth.Previous.Window)
+		}
+		if growth.Deltas != nil {
+			t.Errorf("expected no deltas against a truncated current window, got %+v", growth.Deltas)
+		}
+	})
+
+	t.Run("no previous period", func(t *testing.T) {
+		svc := &service.VideoService{TwitchClient: &mockTwitchClient{videos: videos[:2]}}
+
+		growth, err := svc.GetVideoGrowth(context.Background(), "channel1", model.StatsQuery{Limit: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if growth.Previous.TotalViews != 0 || growth.Deltas != nil {
+			t.Errorf("expected zero previous and no deltas, got %+v / %+v", growth.Previous, growth.Deltas)
+		}
+	})
+
+	t.Run("window capped at n each", func(t *testing.T) {
+		svc := &service.VideoService{TwitchClient: &mockTwitchClient{videos: videos}}
+
+		growth, err := svc.GetVideoGrowth(context.Background(), "channel1", model.StatsQuery{Limit: 5, Since: day(16), Until: day(26)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// current days 21-25 of 16-25, previous days 11-15 of 6-15
+		cur, prev := growth.Current.Window, growth.Previous.Window
+		if cur.VideoCount != 5 || !cur.Truncated || prev.VideoCount != 5 || !prev.Truncated {
+			t.Fatalf("expected 5 videos and truncated per window, got %+v / %+v", cur, prev)
+		}
+		if growth.Previous.TotalViews != 650 {
+			t.Errorf("expected previous total 650, got %d", growth.Previous.TotalViews)
+		}
+		if growth.Deltas != nil {
+			t.Errorf("expected no deltas against a truncated previous window, got %+v", growth.Deltas)
+		}
+	})
+
+	t.Run("no current videos", func(t *testing.T) {
+		svc := &service.VideoService{TwitchClient: &mockTwitchClient{}}
+
+		if _, err := svc.GetVideoGrowth(context.Background(), "channel1", model.StatsQuery{Limit: 2}); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
+
+func TestVideoService_CompareChannels(t *testing.T) {
+	client := &mockChannelClient{
+		videos: map[string][]model.Video{},
+		errs:   map[string]error{"broken": errors.New("fetch failed")},
+	}
+
+	var channels []string
+	for i := 1; i <= 10; i++ {
+		id := "chan" + strconv.Itoa(i)
+		channels = append(channels, id)
+		client.videos[id] = []model.Video{{Title: id, ViewCount: i * 100, Duration: "1h0m0s"}}
+	}
+	channels = append(channels, "broken")
+
+	svc := &service.VideoService{TwitchClient: client, Workers: 3}
+
+	comparison, err := svc.CompareChannels(context.Background(), channels, model.StatsQuery{Limit: 5}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if client.maxInFlight > 3 {
+		t.Errorf("expected at most 3 concurrent fetches, got %d", client.maxInFlight)
+	}
+	if comparison.Metric != service.DefaultCompareMetric {
+		t.Errorf("expected default metric, got %q", comparison.Metric)
+	}
+
+	if len(comparison.Channels) != len(channels) {
+		t.Fatalf("expected %d channel results, got %d", len(channels), len(comparison.Channels))
+	}
+	for i, c := range comparison.Channels {
+		if c.ChannelID != channels[i] {
+			t.Errorf("expected channel %d to be %s, got %s", i, channels[i], c.ChannelID)
+		}
+	}
+	if last := comparison.Channels[len(channels)-1]; last.Stats != nil || last.Error != "fetch failed" {
+		t.Errorf("expected broken channel to report its error, got %+v", last)
+	}
+
+	if len(comparison.Ranking) != 10 {
+		t.Fatalf("expected 10 ranked channels, got %d", len(comparison.Ranking))
+	}
+	if top := comparison.Ranking[0]; top.Rank != 1 || top.ChannelID != "chan10" || top.Value != 1000 {
+		t.Errorf("expected chan10 ranked first with 1000, got %+v", top)
+	}
+
+	if _, err := svc.CompareChannels(context.Background(), []string{"broken"}, model.StatsQuery{Limit: 5}, ""); err == nil {
+		t.Errorf("expected error when every channel fails")
+	}
+	if _, err := svc.CompareChannels(context.Background(), channels, model.StatsQuery{Limit: 5}, "likes"); err == nil {
+		t.Errorf("expected error for unknown metric")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := svc.CompareChannels(ctx, channels, model.StatsQuery{Limit: 5}, ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected cancelled comparison to return context.Canceled, got %v", err)
+	}
+}
+
+func TestVideoService_GetVideoStatsByLogin(t *testing.T) {
+	mockClient := &mockTwitchClient{
+		videos: []model.Video{{Title: "Vid1", ViewCount: 100, Duration: "10m0s"}},
+		users: map[string]model.User{
+			"twitchdev": {ID: "141981764", Login: "twitchdev", DisplayName: "TwitchDev", BroadcasterType: model.BroadcasterTypePartner},
+		},
+	}
+	svc := &service.VideoService{TwitchClient: mockClient}
+
+	stats, err := svc.GetVideoStatsByLogin(context.Background(), "twitchdev", model.StatsQuery{Limit: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mockClient.channelID != "141981764" {
+		t.Errorf("expected videos fetched for resolved id, got %q", mockClient.channelID)
+	}
+	if stats.User == nil || stats.User.DisplayName != "TwitchDev" || stats.TotalViews != 100 {
+		t.Errorf("expected stats with user profile, got %+v", stats)
+	}
+
+	if _, err := svc.GetVideoStatsByLogin(context.Background(), "nobody", model.StatsQuery{Limit: 5}); !errors.Is(err, twitch.ErrChannelNotFound) {
+		t.Errorf("expected ErrChannelNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"fourthfloor/internal/model"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// defaultOpenTimeout is how long Open waits for another process to release the file lock
+const defaultOpenTimeout = time.Second
+
+var (
+	// videosBucket maps a video id to its latest metadata as JSON
+	videosBucket = []byte("videos")
+
+	// snapshotsBucket holds one bucket per video id, mapping the big-endian
+	// UnixNano time of each snapshot to its big-endian view count, so that
+	// cursor order is time order
+	snapshotsBucket = []byte("snapshots")
+)
+
+// BoltStore SnapshotStore kept in a single bbolt file. It is pure Go and safe
+// for concurrent use, but the file can only be opened by one process at a time.
+type BoltStore struct {
+	db      *bolt.DB
+	timeout time.Duration
+
+	mu             sync.Mutex // protects lastCompaction
+	lastCompaction *model.CompactionResult
+}
+
+// Open opens or creates the BoltStore at path.
+func Open(path string, options ...func(*BoltStore)) (*BoltStore, error) {
+	s := &BoltStore{timeout: defaultOpenTimeout}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: s.timeout})
+	if err != nil {
+		return nil, fmt.Errorf("open snapshot store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{videosBucket, snapshotsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open snapshot store %s: %w", path, err)
+	}
+
+	s.db = db
+	return s, nil
+}
+
+// WithOpenTimeout sets how long Open waits for another process to release the file lock.
+func WithOpenTimeout(d time.Duration) func(*BoltStore) {
+	return func(s *BoltStore) { s.timeout = d }
+}
+
+// Record stores a snapshot of each video's view count taken at at. Videos
+// without an id are skipped; a second snapshot at the same time replaces the first.
+func (s *BoltStore) Record(ctx context.Context, at time.Time, videos ...model.Video) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	key := timeKey(at)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(videosBucket)
+		snapshots := tx.Bucket(snapshotsBucket)
+
+		for _, v := range videos {
+			if v.ID == "" {
+				continue
+			}
+
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			if err := meta.Put([]byte(v.ID), data); err != nil {
+				return err
+			}
+
+			b, err := snapshots.CreateBucketIfNotExists([]byte(v.ID))
+			if err != nil {
+				return err
+			}
+			if err := b.Put(key, binary.BigEndian.AppendUint64(nil, uint64(v.ViewCount))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Snapshots returns the snapshots of a video taken within [from, to), oldest first.
+func (s *BoltStore) Snapshots(ctx context.Context, videoID string, from, to time.Time) ([]model.Snapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var snapshots []model.Snapshot
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(snapshotsBucket).Bucket([]byte(videoID))
+		if b == nil {
+			return nil
+		}
+
+		var channelID string
+		if data := tx.Bucket(videosBucket).Get([]byte(videoID)); data != nil {
+			var v model.Video
+			if err := json.Unmarshal(data, &v); err != nil {
+				return err
+			}
+			channelID = v.UserID
+		}
+
+		c := b.Cursor()
+		k, value := c.First()
+		if !from.IsZero() {
+			k, value = c.Seek(timeKey(from))
+		}
+		for ; k != nil; k, value = c.Next() {
+			if !to.IsZero() && bytes.Compare(k, timeKey(to)) >= 0 {
+				break
+			}
+			snapshots = append(snapshots, model.Snapshot{
+				VideoID:   videoID,
+				ChannelID: channelID,
+				ViewCount: int(binary.BigEndian.Uint64(value)),
+				At:        keyTime(k),
+			})
+		}
+		return nil
+	})
+	return snapshots, err
+}
+
+// Video returns the latest recorded metadata of a video.
+func (s *BoltStore) Video(ctx context.Context, videoID string) (model.Video, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return model.Video{}, false, err
+	}
+
+	var (
+		v  model.Video
+		ok bool
+	)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(videosBucket).Get([]byte(videoID))
+		if data == nil {
+			return nil
+		}
+		ok = true
+		return json.Unmarshal(data, &v)
+	})
+	return v, ok, err
+}
+
+// Close closes the underlying file.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// timeKey encodes t so that keys sort in time order
+func timeKey(t time.Time) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(t.UnixNano()))
+}
+
+// keyTime decodes a key written by timeKey
+func keyTime(k []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(k))).UTC()
+}
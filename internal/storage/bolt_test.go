@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fourthfloor/internal/model"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestStore opens a BoltStore in a temporary directory, closed when the test ends
+func openTestStore(t *testing.T) *BoltStore {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "snapshots.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// ---- Tests ----
+
+func TestBoltStoreSnapshots(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	start := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, views := range []int{10, 25, 40, 70} {
+		at := start.Add(time.Duration(i) * time.Hour)
+		videos := []model.Video{
+			{ID: "v1", UserID: "chan", Title: "first", ViewCount: views},
+			{ID: "v2", UserID: "chan", ViewCount: 1000 + views},
+			{ViewCount: 5}, // no id, skipped
+		}
+		if err := s.Record(ctx, at, videos...); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		videoID   string
+		from, to  time.Time
+		wantViews []int
+	}{
+		{name: "all", videoID: "v1", wantViews: []int{10, 25, 40, 70}},
+		{name: "from", videoID: "v1", from: start.Add(90 * time.Minute), wantViews: []int{40, 70}},
+		{name: "to excludes its end", videoID: "v1", to: start.Add(2 * time.Hour), wantViews: []int{10, 25}},
+		{name: "range", videoID: "v2", from: start.Add(time.Hour), to: start.Add(3 * time.Hour), wantViews: []int{1025, 1040}},
+		{name: "unknown video", videoID: "v3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Snapshots(ctx, tt.videoID, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.wantViews) {
+				t.Fatalf("wanted %d snapshots, got %+v", len(tt.wantViews), got)
+			}
+			for i, snap := range got {
+				if snap.ViewCount != tt.wantViews[i] || snap.VideoID != tt.videoID || snap.ChannelID != "chan" {
+					t.Errorf("snapshot %d: unexpected %+v", i, snap)
+				}
+				if i > 0 && !snap.At.After(got[i-1].At) {
+					t.Errorf("wanted snapshots oldest first, got %+v", got)
+				}
+			}
+		})
+	}
+}
+
+func TestBoltStoreVideo(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	at := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, ok, err := s.Video(ctx, "v1"); ok || err != nil {
+		t.Errorf("wanted unknown video, got ok=%v err=%v", ok, err)
+	}
+
+	s.Record(ctx, at, model.Video{ID: "v1", Title: "old", ViewCount: 1})
+	s.Record(ctx, at.Add(time.Minute), model.Video{ID: "v1", Title: "renamed", ViewCount: 2})
+
+	v, ok, err := s.Video(ctx, "v1")
+	if !ok || err != nil || v.Title != "renamed" || v.ViewCount != 2 {
+		t.Errorf("wanted latest metadata, got %+v ok=%v err=%v", v, ok, err)
+	}
+}
+
+func TestBoltStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshots.db")
+	ctx := context.Background()
+	at := time.Date(2025, 9, 1, 12, 30, 0, 123, time.UTC)
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := s.Record(ctx, at, model.Video{ID: "v1", ViewCount: 42}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	// the file is locked while open
+	if _, err := Open(path, WithOpenTimeout(10*time.Millisecond)); err == nil {
+		t.Error("wanted error opening a store already open")
+	}
+	s.Close()
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+
+	got, err := s.Snapshots(ctx, "v1", time.Time{}, time.Time{})
+	if err != nil || len(got) != 1 || got[0].ViewCount != 42 || !got[0].At.Equal(at) {
+		t.Errorf("wanted snapshot kept across reopen, got %+v err=%v", got, err)
+	}
+}
+
+func TestBoltStoreCancelledContext(t *testing.T) {
+	s := openTestStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.Record(ctx, time.Now(), model.Video{ID: "v1"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted context.Canceled, got %v", err)
+	}
+	if _, err := s.Snapshots(ctx, "v1", time.Time{}, time.Time{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/twitch"
+	"log"
+	"time"
+)
+
+// RecordingClient TwitchAPIClientInterface wrapping another implementation and
+// recording a snapshot of every video it returns. Recording failures are logged
+// and never fail the call. Place it beneath any cache so that only responses
+// actually fetched from Twitch are recorded.
+type RecordingClient struct {
+	next  twitch.TwitchAPIClientInterface
+	store SnapshotStore
+	now   func() time.Time
+}
+
+// NewRecordingClient creates a RecordingClient recording videos fetched by next into store.
+func NewRecordingClient(next twitch.TwitchAPIClientInterface, store SnapshotStore) *RecordingClient {
+	return &RecordingClient{next: next, store: store, now: time.Now}
+}
+
+// FetchVideos fetches videos from next and records them.
+func (c *RecordingClient) FetchVideos(ctx context.Context, channelID string, limit int, filter model.VideoFilter) ([]model.Video, error) {
+	videos, err := c.next.FetchVideos(ctx, channelID, limit, filter)
+	if err == nil {
+		c.record(ctx, videos)
+	}
+	return videos, err
+}
+
+// FetchVideoPage fetches a page from next and records its videos.
+func (c *RecordingClient) FetchVideoPage(ctx context.Context, channelID string, first int, filter model.VideoFilter, cursor string) (model.VideoResponse, error) {
+	page, err := c.next.FetchVideoPage(ctx, channelID, first, filter, cursor)
+	if err == nil {
+		c.record(ctx, page.Data)
+	}
+	return page, err
+}
+
+// FetchUserByLogin fetches the user from next; users are not recorded.
+func (c *RecordingClient) FetchUserByLogin(ctx context.Context, login string) (model.User, error) {
+	return c.next.FetchUserByLogin(ctx, login)
+}
+
+// record stores videos even if the caller has gone, since they were fetched anyway
+func (c *RecordingClient) record(ctx context.Context, videos []model.Video) {
+	if len(videos) == 0 {
+		return
+	}
+	if err := c.store.Record(context.WithoutCancel(ctx), c.now(), videos...); err != nil {
+		log.Printf("record %d snapshots: %v", len(videos), err)
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fourthfloor/internal/model"
+	"testing"
+	"time"
+)
+
+// ---- Mocks ----
+
+// mockTwitchClient returns fixed videos or an error
+type mockTwitchClient struct {
+	videos []model.Video
+	err    error
+}
+
+func (m *mockTwitchClient) FetchVideos(ctx context.Context, channelID string, limit int, filter model.VideoFilter) ([]model.Video, error) {
+	return m.videos, m.err
+}
+
+func (m *mockTwitchClient) FetchVideoPage(ctx context.Context, channelID string, first int, filter model.VideoFilter, cursor string) (model.VideoResponse, error) {
+	return model.VideoResponse{Data: m.videos}, m.err
+}
+
+func (m *mockTwitchClient) FetchUserByLogin(ctx context.Context, login string) (model.User, error) {
+	return model.User{Login: login}, m.err
+}
+
+// failingStore SnapshotStore whose every call fails
+type failingStore struct{ SnapshotStore }
+
+func (failingStore) Record(context.Context, time.Time, ...model.Video) error {
+	return errors.New("store down")
+}
+
+// ---- Tests ----
+
+func TestRecordingClient(t *testing.T) {
+	store := openTestStore(t)
+	next := &mockTwitchClient{videos: []model.Video{{ID: "v1", UserID: "chan", ViewCount: 10}}}
+	c := NewRecordingClient(next, store)
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	// recorded even when the caller has gone
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.FetchVideos(ctx, "chan", 1, model.VideoFilter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	next.videos[0].ViewCount = 25
+	if _, err := c.FetchVideoPage(context.Background(), "chan", 1, model.VideoFilter{}, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	next.err = errors.New("fetch failed")
+	now = now.Add(time.Hour)
+	if _, err := c.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{}); err == nil {
+		t.Fatal("wanted error, got nil")
+	}
+
+	got, err := store.Snapshots(context.Background(), "v1", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].ViewCount != 10 || got[1].ViewCount != 25 || !got[1].At.Equal(now.Add(-time.Hour)) {
+		t.Errorf("wanted a snapshot per successful fetch, got %+v", got)
+	}
+}
+
+func TestRecordingClientFailingStore(t *testing.T) {
+	next := &mockTwitchClient{videos: []model.Video{{ID: "v1"}}}
+	c := NewRecordingClient(next, failingStore{})
+
+	videos, err := c.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{})
+	if err != nil || len(videos) != 1 {
+		t.Errorf("wanted videos returned despite the store failing, got %v err=%v", videos, err)
+	}
+}
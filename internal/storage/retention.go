@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"fourthfloor/internal/model"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// RetentionPolicy how long snapshots are kept at each resolution. Snapshots
+// older than Raw are rolled up to the last one of each UTC hour, and those
+// older than Hourly to the last one of each UTC day, which are kept forever.
+// A zero duration disables that roll-up.
+type RetentionPolicy struct {
+	Raw    time.Duration
+	Hourly time.Duration
+}
+
+// DefaultRetention keeps raw snapshots for 7 days and hourly ones for 90
+var DefaultRetention = RetentionPolicy{Raw: 7 * 24 * time.Hour, Hourly: 90 * 24 * time.Hour}
+
+// Compactor SnapshotStore whose old snapshots can be rolled up under a RetentionPolicy
+type Compactor interface {
+	// Compact rolls up the snapshots policy says are due at now.
+	Compact(ctx context.Context, now time.Time, policy RetentionPolicy) (model.CompactionResult, error)
+}
+
+// Validate reports whether the policy's durations are usable: neither may be
+// negative, and when both roll-ups are enabled Hourly must exceed Raw, or
+// snapshots still within the raw retention would be rolled up to days.
+func (p RetentionPolicy) Validate() error {
+	switch {
+	case p.Raw < 0 || p.Hourly < 0:
+		return fmt.Errorf("retention durations must not be negative, got raw %s and hourly %s", p.Raw, p.Hourly)
+	case p.Raw > 0 && p.Hourly > 0 && p.Hourly <= p.Raw:
+		return fmt.Errorf("hourly retention %s must be longer than raw retention %s", p.Hourly, p.Raw)
+	}
+	return nil
+}
+
+// cutoffs returns the times before which snapshots are rolled up to hours and
+// to days, aligned to whole buckets; a zero time disables that roll-up. The
+// daily cutoff is never later than the hourly one, so that a policy failing
+// Validate still never rolls up snapshots within the raw retention to days.
+func (p RetentionPolicy) cutoffs(now time.Time) (hourly, daily time.Time) {
+	if p.Raw > 0 {
+		hourly = now.Add(-p.Raw).Truncate(time.Hour)
+	}
+	if p.Hourly > 0 {
+		daily = now.Add(-p.Hourly).Truncate(24 * time.Hour)
+		if !hourly.IsZero() && daily.After(hourly) {
+			daily = hourly.Truncate(24 * time.Hour)
+		}
+	}
+	return hourly, daily
+}
+
+// bucket returns the roll-up bucket t falls in, and false if it is kept raw
+func (p RetentionPolicy) bucket(t, hourly, daily time.Time) (time.Time, bool) {
+	switch {
+	case !daily.IsZero() && t.Before(daily):
+		return t.Truncate(24 * time.Hour), true
+	case !hourly.IsZero() && t.Before(hourly):
+		return t.Truncate(time.Hour), true
+	}
+	return time.Time{}, false
+}
+
+// Compact rolls up snapshots older than the policy's raw retention to the last
+// one of each hour, and older than its hourly retention to the last one of each
+// day. Each video is compacted in its own transaction so that recording is
+// never blocked for long; a cancelled ctx stops between videos.
+func (s *BoltStore) Compact(ctx context.Context, now time.Time, policy RetentionPolicy) (model.CompactionResult, error) {
+	start := time.Now()
+	result := model.CompactionResult{At: now}
+	hourly, daily := policy.cutoffs(now)
+
+	var ids [][]byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).ForEachBucket(func(id []byte) error {
+			ids = append(ids, append([]byte(nil), id...))
+			return nil
+		})
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		err := s.db.Update(func(tx *bolt.Tx) error {
+			b := tx.Bucket(snapshotsBucket).Bucket(id)
+			if b == nil {
+				return nil
+			}
+
+			// keep the last snapshot of each bucket, deleting after the scan
+			// since a bucket must not change under its cursor
+			var (
+				stale      [][]byte
+				prev       []byte
+				prevBucket time.Time
+			)
+			c := b.Cursor()
+			for k, _ := c.First(); k != nil; k, _ = c.Next() {
+				at, ok := policy.bucket(keyTime(k), hourly, daily)
+				if !ok {
+					break
+				}
+				if prev != nil && at.Equal(prevBucket) {
+					stale = append(stale, prev)
+				}
+				prev, prevBucket = append([]byte(nil), k...), at
+			}
+
+			for _, k := range stale {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+			result.Removed += len(stale)
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+		result.Videos++
+	}
+
+	result.DurationSeconds = time.Since(start).Seconds()
+	s.mu.Lock()
+	s.lastCompaction = &result
+	s.mu.Unlock()
+	return result, nil
+}
+
+// Stats returns the number of videos and snapshots stored and the size of the
+// file, with the result of the last compaction.
+func (s *BoltStore) Stats(ctx context.Context) (model.StorageStats, error) {
+	if err := ctx.Err(); err != nil {
+		return model.StorageStats{}, err
+	}
+
+	var stats model.StorageStats
+	err := s.db.View(func(tx *bolt.Tx) error {
+		stats.FileSizeBytes = tx.Size()
+		return tx.Bucket(snapshotsBucket).ForEachBucket(func(id []byte) error {
+			stats.Videos++
+			stats.Snapshots += tx.Bucket(snapshotsBucket).Bucket(id).Stats().KeyN
+			return nil
+		})
+	})
+	if err != nil {
+		return model.StorageStats{}, err
+	}
+
+	dbStats := s.db.Stats()
+	stats.FreeBytes = int64(dbStats.FreePageN+dbStats.PendingPageN) * int64(s.db.Info().PageSize)
+
+	s.mu.Lock()
+	if s.lastCompaction != nil {
+		last := *s.lastCompaction
+		stats.LastCompaction = &last
+	}
+	s.mu.Unlock()
+	return stats, nil
+}
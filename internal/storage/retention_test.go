@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fourthfloor/internal/model"
+	"testing"
+	"time"
+)
+
+// recordAt records a snapshot of video id at each of the given times
+func recordAt(t *testing.T, s *BoltStore, id string, times ...time.Time) {
+	t.Helper()
+	for i, at := range times {
+		if err := s.Record(context.Background(), at, model.Video{ID: id, ViewCount: i}); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+}
+
+// ---- Tests ----
+
+func TestBoltStoreCompact(t *testing.T) {
+	now := time.Date(2025, 12, 1, 0, 30, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2025, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name        string
+		policy      RetentionPolicy
+		wantRemoved int
+		wantKept    []time.Time
+	}{
+		{
+			name:        "default tiers",
+			policy:      DefaultRetention,
+			wantRemoved: 4,
+			wantKept: []time.Time{
+				at(8, 1, 16, 0),                     // last of its day
+				at(8, 2, 10, 0),                     // only one that day
+				at(10, 1, 10, 40), at(10, 1, 11, 0), // last of each hour
+				at(11, 30, 10, 0), at(11, 30, 10, 20), // raw
+			},
+		},
+		{
+			name:        "hourly only",
+			policy:      RetentionPolicy{Raw: 7 * 24 * time.Hour},
+			wantRemoved: 2,
+			wantKept: []time.Time{
+				at(8, 1, 0, 0), at(8, 1, 8, 0), at(8, 1, 16, 0),
+				at(8, 2, 10, 0),
+				at(10, 1, 10, 40), at(10, 1, 11, 0),
+				at(11, 30, 10, 0), at(11, 30, 10, 20),
+			},
+		},
+		{
+			name:        "hourly shorter than raw",
+			policy:      RetentionPolicy{Raw: 7 * 24 * time.Hour, Hourly: 24 * time.Hour},
+			wantRemoved: 5,
+			wantKept: []time.Time{
+				at(8, 1, 16, 0),
+				at(8, 2, 10, 0),
+				at(10, 1, 11, 0),
+				at(11, 30, 10, 0), at(11, 30, 10, 20), // raw is never rolled up to days
+			},
+		},
+		{
+			name:   "disabled",
+			policy: RetentionPolicy{},
+			wantKept: []time.Time{
+				at(8, 1, 0, 0), at(8, 1, 8, 0), at(8, 1, 16, 0),
+				at(8, 2, 10, 0),
+				at(10, 1, 10, 0), at(10, 1, 10, 20), at(10, 1, 10, 40), at(10, 1, 11, 0),
+				at(11, 30, 10, 0), at(11, 30, 10, 20),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openTestStore(t)
+			ctx := context.Background()
+			recordAt(t, s, "v1",
+				at(8, 1, 0, 0), at(8, 1, 8, 0), at(8, 1, 16, 0),
+				at(8, 2, 10, 0),
+				at(10, 1, 10, 0), at(10, 1, 10, 20), at(10, 1, 10, 40), at(10, 1, 11, 0),
+				at(11, 30, 10, 0), at(11, 30, 10, 20),
+			)
+
+			result, err := s.Compact(ctx, now, tt.policy)
+			if err != nil {
+				t.Fatalf("compact: %v", err)
+			}
+			if result.Removed != tt.wantRemoved || result.Videos != 1 || !result.At.Equal(now) {
+				t.Errorf("unexpected result %+v", result)
+			}
+
+			got, _ := s.Snapshots(ctx, "v1", time.Time{}, time.Time{})
+			if len(got) != len(tt.wantKept) {
+				t.Fatalf("wanted %d snapshots kept, got %+v", len(tt.wantKept), got)
+			}
+			for i, snap := range got {
+				if !snap.At.Equal(tt.wantKept[i]) {
+					t.Errorf("snapshot %d: wanted %s, got %s", i, tt.wantKept[i], snap.At)
+				}
+			}
+
+			// compacting again finds nothing more to roll up
+			if result, _ := s.Compact(ctx, now, tt.policy); result.Removed != 0 {
+				t.Errorf("wanted compaction idempotent, removed %d", result.Removed)
+			}
+		})
+	}
+}
+
+func TestRetentionPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  RetentionPolicy
+		wantErr bool
+	}{
+		{name: "default", policy: DefaultRetention},
+		{name: "disabled", policy: RetentionPolicy{}},
+		{name: "raw only", policy: RetentionPolicy{Raw: time.Hour}},
+		{name: "hourly only", policy: RetentionPolicy{Hourly: time.Hour}},
+		{name: "hourly shorter than raw", policy: RetentionPolicy{Raw: 7 * 24 * time.Hour, Hourly: 24 * time.Hour}, wantErr: true},
+		{name: "hourly equal to raw", policy: RetentionPolicy{Raw: time.Hour, Hourly: time.Hour}, wantErr: true},
+		{name: "negative", policy: RetentionPolicy{Raw: -time.Hour}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("wanted error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBoltStoreStats(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+	start := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	stats, err := s.Stats(ctx)
+	if err != nil || stats.Videos != 0 || stats.Snapshots != 0 || stats.LastCompaction != nil {
+		t.Fatalf("wanted empty stats, got %+v err=%v", stats, err)
+	}
+
+	var times []time.Time
+	for i := range 500 {
+		times = append(times, start.Add(time.Duration(i)*time.Minute))
+	}
+	recordAt(t, s, "v1", times...)
+	recordAt(t, s, "v2", start)
+
+	stats, _ = s.Stats(ctx)
+	if stats.Videos != 2 || stats.Snapshots != 501 || stats.FileSizeBytes <= 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	if _, err := s.Compact(ctx, start.Add(30*24*time.Hour), DefaultRetention); err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	stats, _ = s.Stats(ctx)
+	// 500 minutes span 9 hours
+	if stats.Snapshots != 10 || stats.LastCompaction == nil || stats.LastCompaction.Removed != 491 {
+		t.Errorf("wanted stats after compaction, got %+v last %+v", stats, stats.LastCompaction)
+	}
+	if stats.FreeBytes <= 0 {
+		t.Errorf("wanted space freed by compaction reported, got %+v", stats)
+	}
+}
+
+func TestBoltStoreCompactCancelled(t *testing.T) {
+	s := openTestStore(t)
+	recordAt(t, s, "v1", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Compact(ctx, time.Now(), DefaultRetention); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted context.Canceled, got %v", err)
+	}
+}
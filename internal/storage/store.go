@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+	"fourthfloor/internal/model"
+	"time"
+)
+
+// SnapshotStore records the view counts of fetched videos over time
+type SnapshotStore interface {
+	// Record stores a snapshot of each video's view count taken at at, along
+	// with the video itself as its latest known metadata.
+	Record(ctx context.Context, at time.Time, videos ...model.Video) error
+
+	// Snapshots returns the snapshots of a video taken within [from, to), oldest
+	// first. A zero from or to leaves that end unbounded.
+	Snapshots(ctx context.Context, videoID string, from, to time.Time) ([]model.Snapshot, error)
+
+	// Video returns the latest recorded metadata of a video, reporting false if
+	// it was never recorded.
+	Video(ctx context.Context, videoID string) (model.Video, bool, error)
+
+	// Close releases the store.
+	Close() error
+}
@@ -2,17 +2,38 @@ package twitch
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"fourthfloor/internal/model"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
+const (
+	// maxPageSize is the largest page size Helix accepts for the "first" parameter.
+	maxPageSize = 100
+
+	// defaultTimeout bounds every outbound request, including retries' individual attempts.
+	defaultTimeout = 10 * time.Second
+
+	// defaultCallTimeout bounds a whole Helix call: rate limit wait, retries and token replay.
+	defaultCallTimeout = 30 * time.Second
+)
+
+// TwitchAPIClientInterface defines the interface for fetching videos and users from Twitch.
+// FetchVideos follows pagination cursors so limit may exceed a single Helix page;
+// FetchVideoPage fetches a single page for callers that control paging themselves.
 type TwitchAPIClientInterface interface {
-	FetchVideos(channelID string, limit int) ([]model.Video, error)
+	FetchVideos(ctx context.Context, channelID string, limit int, filter model.VideoFilter) ([]model.Video, error)
+	FetchVideoPage(ctx context.Context, channelID string, first int, filter model.VideoFilter, cursor string) (model.VideoResponse, error)
+	FetchUserByLogin(ctx context.Context, login string) (model.User, error)
 }
 
 // TwitchAPIClient represents a Twitch API client with token management.
@@ -21,13 +42,34 @@ type TwitchAPIClient struct {
 	ClientSecret string
 	Token        string
 	BaseURL      string
+	UsersURL     string
+	AuthURL      string
 
 	expires          time.Time
 	now              func() time.Time
 	refreshTokenFunc func() (string, time.Time, error)
 	httpClient       *http.Client
+	callTimeout      time.Duration
+	limiter          *RateLimiter
+	retry            RetryPolicy
+	sleep            func(context.Context, time.Duration) error
+
+	mu         sync.Mutex // protects Token, expires and refreshing
+	refreshing *refreshCall
+
+	users    map[string]cachedUser
+	usersTTL time.Duration
+	maxUsers int
+	usersMu  sync.Mutex // protects users cache
 
-	mu sync.Mutex // protects token refresh
+	stopValidator func()
+	validatorMu   sync.Mutex // protects stopValidator
+}
+
+// refreshCall a token refresh in flight; err is set before done is closed
+type refreshCall struct {
+	done chan struct{}
+	err  error
 }
 
 // NewTwitchAPIClient creates a TwitchAPIClient with default Twitch API URL.
@@ -36,8 +78,17 @@ func NewTwitchAPIClient(clientID, clientSecret string, options ...func(*TwitchAP
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
 		BaseURL:      "https://api.twitch.tv/helix/videos",
-		httpClient:   http.DefaultClient,
+		UsersURL:     "https://api.twitch.tv/helix/users",
+		AuthURL:      "https://id.twitch.tv/oauth2",
+		httpClient:   newHTTPClient(),
+		callTimeout:  defaultCallTimeout,
+		limiter:      NewRateLimiter(defaultRateLimit, defaultMaxWait),
+		retry:        DefaultRetryPolicy,
+		sleep:        sleepContext,
 		now:          time.Now,
+		users:        make(map[string]cachedUser),
+		usersTTL:     defaultUsersTTL,
+		maxUsers:     defaultMaxUsers,
 	}
 
 	c.refreshTokenFunc = c.defaultRefreshTokenFunc
@@ -53,6 +104,72 @@ func WithBaseURL(url string) func(*TwitchAPIClient) {
 	return func(c *TwitchAPIClient) { c.BaseURL = url }
 }
 
+// WithUsersURL allows overriding the users endpoint URL (useful for tests)
+func WithUsersURL(url string) func(*TwitchAPIClient) {
+	return func(c *TwitchAPIClient) { c.UsersURL = url }
+}
+
+// WithAuthURL overrides the OAuth base URL the token and validate endpoints live under
+func WithAuthURL(url string) func(*TwitchAPIClient) {
+	return func(c *TwitchAPIClient) { c.AuthURL = url }
+}
+
+// WithHTTPClient sets the HTTP client used for every Helix and OAuth request.
+func WithHTTPClient(hc *http.Client) func(*TwitchAPIClient) {
+	return func(c *TwitchAPIClient) { c.httpClient = hc }
+}
+
+// WithTimeout sets the timeout of each outbound request.
+func WithTimeout(d time.Duration) func(*TwitchAPIClient) {
+	return func(c *TwitchAPIClient) {
+		hc := *c.httpClient // copy so a shared client is left untouched
+		hc.Timeout = d
+		c.httpClient = &hc
+	}
+}
+
+// WithCallTimeout sets the deadline for a whole Helix call, including queueing
+// for rate budget and retries. The caller's context may set an earlier one.
+func WithCallTimeout(d time.Duration) func(*TwitchAPIClient) {
+	return func(c *TwitchAPIClient) { c.callTimeout = d }
+}
+
+// WithProxy routes every outbound request through proxy instead of the proxy
+// taken from the HTTP_PROXY / HTTPS_PROXY / NO_PROXY environment. It has no
+// effect on a client whose transport is not an *http.Transport.
+func WithProxy(proxy *url.URL) func(*TwitchAPIClient) {
+	return func(c *TwitchAPIClient) {
+		hc := *c.httpClient
+		var t *http.Transport
+		switch rt := hc.Transport.(type) {
+		case nil:
+			t = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			t = rt.Clone()
+		default:
+			return
+		}
+		t.Proxy = http.ProxyURL(proxy)
+		hc.Transport = t
+		c.httpClient = &hc
+	}
+}
+
+// WithUsersTTL sets how long resolved users are cached.
+func WithUsersTTL(ttl time.Duration) func(*TwitchAPIClient) {
+	return func(c *TwitchAPIClient) { c.usersTTL = ttl }
+}
+
+// WithMaxUsers sets how many resolved users are cached; zero disables caching.
+func WithMaxUsers(n int) func(*TwitchAPIClient) {
+	return func(c *TwitchAPIClient) { c.maxUsers = n }
+}
+
+// WithRateLimiter shares a rate limiter between clients using the same app token.
+func WithRateLimiter(l *RateLimiter) func(*TwitchAPIClient) {
+	return func(c *TwitchAPIClient) { c.limiter = l }
+}
+
 // WithRefreshFunc allows injecting a custom token refresh function (useful for tests)
 func WithRefreshFunc(fn func() (string, time.Time, error)) func(*TwitchAPIClient) {
 	return func(c *TwitchAPIClient) { c.refreshTokenFunc = fn }
@@ -63,21 +180,79 @@ func WithExpires(t time.Time) func(*TwitchAPIClient) {
 	return func(c *TwitchAPIClient) { c.expires = t }
 }
 
-// EnsureTokenValid refreshes the token if expired or near expiry.
-func (c *TwitchAPIClient) EnsureTokenValid() error {
+// newHTTPClient default HTTP client with a timeout and proxy taken from the environment
+func newHTTPClient() *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	return &http.Client{Timeout: defaultTimeout, Transport: t}
+}
+
+// RateLimit returns the current state of the client's Helix rate budget.
+func (c *TwitchAPIClient) RateLimit() RateLimitState {
+	return c.limiter.State()
+}
+
+// EnsureTokenValid refreshes the token if expired or near expiry. A caller
+// waiting on a refresh stops waiting once ctx is done.
+func (c *TwitchAPIClient) EnsureTokenValid(ctx context.Context) error {
+	return c.refresh(ctx, func() bool { return c.now().After(c.expires) })
+}
+
+// forceRefresh refreshes the token after Twitch rejected stale. If another call
+// has already replaced stale the current token is kept, so concurrent 401s
+// trigger a single refresh.
+func (c *TwitchAPIClient) forceRefresh(ctx context.Context, stale string) error {
+	return c.refresh(ctx, func() bool { return c.Token == stale })
+}
+
+// refresh fetches a new token if needed, called under the lock, reports one is
+// due. The fetch runs outside the lock and is shared by every caller needing a
+// token meanwhile; it is not tied to any caller's context, so a caller giving
+// up on ctx leaves it to finish for the others.
+func (c *TwitchAPIClient) refresh(ctx context.Context, needed func() bool) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	if !needed() {
+		c.mu.Unlock()
+		return nil
+	}
 
-	if c.now().After(c.expires) {
-		newToken, newExpires, err := c.refreshTokenFunc()
-		if err != nil {
-			return fmt.Errorf("failed to refresh token: %w", err)
+	call := c.refreshing
+	if call == nil {
+		call = &refreshCall{done: make(chan struct{})}
+		c.refreshing = call
+
+		go func() {
+			token, expires, err := c.refreshTokenFunc()
+
+			c.mu.Lock()
+			if err == nil {
+				c.Token, c.expires = token, expires
+			}
+			c.refreshing = nil
+			c.mu.Unlock()
+
+			call.err = err
+			close(call.done)
+		}()
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return fmt.Errorf("failed to refresh token: %w", call.err)
 		}
-		c.Token = newToken
-		c.expires = newExpires
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+}
 
-	return nil
+// currentToken returns the token under the refresh lock
+func (c *TwitchAPIClient) currentToken() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Token
 }
 
 // defaultRefreshTokenFunc default token refresh function if one is not provided
@@ -98,14 +273,22 @@ func (c *TwitchAPIClient) fetchToken() (string, int, error) {
 	}
 
 	// post request to twitch oauth2 API
-	data := fmt.Sprintf(
-		"client_id=%s&client_secret=%s&grant_type=client_credentials",
-		c.ClientID, c.ClientSecret,
-	)
-	req, _ := http.NewRequest("POST", "https://id.twitch.tv/oauth2/token", bytes.NewBufferString(data))
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	resp, err := http.DefaultClient.Do(req)
+	form := url.Values{}
+	form.Set("client_id", c.ClientID)
+	form.Set("client_secret", c.ClientSecret)
+	form.Set("grant_type", "client_credentials")
+
+	// the token is shared by every caller, so its refresh is not tied to any one
+	// caller's context; it gets its own call deadline instead, which also bounds
+	// an injected HTTP client without a timeout
+	ctx, cancel := context.WithTimeout(context.Background(), c.callTimeout)
+	defer cancel()
+
+	resp, err := c.doWithRetry(ctx, func() (*http.Response, error) {
+		req, _ := http.NewRequestWithContext(ctx, "POST", c.AuthURL+"/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return c.httpClient.Do(req)
+	})
 	if err != nil {
 		return "", 0, err
 	}
@@ -125,32 +308,150 @@ func (c *TwitchAPIClient) fetchToken() (string, int, error) {
 	return t.AccessToken, t.ExpiresIn, nil
 }
 
-// FetchVideos fetches videos for a channel, ensuring a valid token first.
-func (c *TwitchAPIClient) FetchVideos(channelID string, limit int) ([]model.Video, error) {
-	if err := c.EnsureTokenValid(); err != nil {
-		return nil, err
+// FetchVideos fetches up to limit videos for a channel matching filter, ensuring a valid token first.
+// Helix caps a page at 100 videos, so the pagination cursor is followed until limit
+// videos are collected or the channel has no more videos.
+func (c *TwitchAPIClient) FetchVideos(ctx context.Context, channelID string, limit int, filter model.VideoFilter) ([]model.Video, error) {
+	var videos []model.Video
+	cursor := ""
+
+	for len(videos) < limit {
+		page, err := c.FetchVideoPage(ctx, channelID, min(limit-len(videos), maxPageSize), filter, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		videos = append(videos, page.Data...)
+
+		// no cursor or empty page means the channel has run out of videos
+		cursor = page.Pagination.Cursor
+		if cursor == "" || len(page.Data) == 0 {
+			break
+		}
+	}
+
+	if len(videos) > limit {
+		videos = videos[:limit]
+	}
+
+	return videos, nil
+}
+
+// FetchVideoPage fetches a single page of at most first videos starting after cursor,
+// ensuring a valid token first. An empty cursor fetches the first page.
+// Helix only accepts a language filter together with game_id, so filter.Language
+// is applied to the fetched videos instead; pages left empty by it are skipped,
+// so that an empty page still means the channel has run out of videos.
+func (c *TwitchAPIClient) FetchVideoPage(ctx context.Context, channelID string, first int, filter model.VideoFilter, cursor string) (model.VideoResponse, error) {
+	if err := c.EnsureTokenValid(ctx); err != nil {
+		return model.VideoResponse{}, err
 	}
 
 	log.Printf("Fetching videos")
 
-	req, _ := http.NewRequest("GET", fmt.Sprintf("%s?user_id=%s&first=%d", c.BaseURL, channelID, limit), nil)
-	req.Header.Set("Client-ID", c.ClientID)
-	req.Header.Set("Authorization", "Bearer "+c.Token)
+	query := url.Values{}
+	query.Set("user_id", channelID)
+	query.Set("first", strconv.Itoa(first))
+	if filter.Type != "" {
+		query.Set("type", string(filter.Type))
+	}
+	if filter.Period != "" {
+		query.Set("period", string(filter.Period))
+	}
+	if filter.Sort != "" {
+		query.Set("sort", string(filter.Sort))
+	}
+
+	for {
+		if cursor != "" {
+			query.Set("after", cursor)
+		}
+
+		var result model.VideoResponse
+		if err := c.helixGet(ctx, c.BaseURL, query, &result); err != nil {
+			return model.VideoResponse{}, err
+		}
+		if filter.Language == "" || len(result.Data) == 0 {
+			return result, nil
+		}
+
+		result.Data = filterLanguage(result.Data, filter.Language)
+		cursor = result.Pagination.Cursor
+		if len(result.Data) > 0 || cursor == "" {
+			return result, nil
+		}
+	}
+}
+
+// filterLanguage returns the videos in lang
+func filterLanguage(videos []model.Video, lang string) []model.Video {
+	var matched []model.Video
+	for _, v := range videos {
+		if strings.EqualFold(v.Language, lang) {
+			matched = append(matched, v)
+		}
+	}
+	return matched
+}
+
+// helixGet sends an authenticated GET to a Helix endpoint and decodes the JSON
+// response into out, retrying transient failures. A 401 means Twitch no longer
+// accepts the token, so it is refreshed and the request replayed once. The whole
+// call is bounded by the client's call timeout.
+func (c *TwitchAPIClient) helixGet(ctx context.Context, endpoint string, query url.Values, out any) error {
+	ctx, cancel := context.WithTimeout(ctx, c.callTimeout)
+	defer cancel()
 
-	resp, err := c.httpClient.Do(req)
+	resp, token, err := c.helixSend(ctx, endpoint, query)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		if err := c.forceRefresh(ctx, token); err != nil {
+			return err
+		}
+		if resp, _, err = c.helixSend(ctx, endpoint, query); err != nil {
+			return err
+		}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("twitch API returned %d", resp.StatusCode)
+		return newAPIError(resp, c.now())
 	}
 
-	var result model.VideoResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
-	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// helixSend sends an authenticated GET with retries and returns the response
+// along with the token it was sent with. Each attempt takes a token from the
+// rate limiter, which is then updated from the response's Ratelimit-* headers.
+func (c *TwitchAPIClient) helixSend(ctx context.Context, endpoint string, query url.Values) (*http.Response, string, error) {
+	token := c.currentToken()
+
+	resp, err := c.doWithRetry(ctx, func() (*http.Response, error) {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
 
-	return result.Data, nil
+		req, _ := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+query.Encode(), nil)
+		req.Header.Set("Client-ID", c.ClientID)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		c.limiter.Update(resp.Header)
+		return resp, nil
+	})
+
+	// Twitch could not be reached; the caller's own cancellation and local rate
+	// limiting are reported as they are
+	if err != nil && ctx.Err() == nil && !errors.Is(err, ErrRateLimited) {
+		err = fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
+	}
+	return resp, token, err
 }
@@ -3,7 +3,9 @@
 package twitch_test
 
 import (
+	"context"
 	"fourthfloor/internal/config"
+	"fourthfloor/internal/model"
 	"testing"
 
 	"fourthfloor/internal/twitch"
@@ -17,7 +19,7 @@ func TestFetchVideos_Integration(t *testing.T) {
 	// number of videos to return
 	limit := 10
 
-	videos, err := client.FetchVideos(cfg.ChannelID, limit)
+	videos, err := client.FetchVideos(context.Background(), cfg.FirstChannelID(), limit, model.VideoFilter{})
 	if err != nil {
 		t.Fatalf("FetchVideos failed: %v", err)
 	}
@@ -1,10 +1,15 @@
 package twitch_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -72,7 +77,7 @@ func TestFetchVideos(t *testing.T) {
 		twitch.WithRefreshFunc(refresh),
 	)
 
-	videos, err := client.FetchVideos("fake-channel", 1)
+	videos, err := client.FetchVideos(context.Background(), "fake-channel", 1, model.VideoFilter{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -82,6 +87,42 @@ func TestFetchVideos(t *testing.T) {
 	}
 }
 
+func TestSlowTokenRefreshRespectsContext(t *testing.T) {
+	release := make(chan struct{})
+	var refreshes atomic.Int32
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL("http://helix.invalid/videos"),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			refreshes.Add(1)
+			<-release
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	// a caller gives up on its own deadline while the refresh is still running
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := client.EnsureTokenValid(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wanted context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("wanted the wait cut short by the deadline, took %v", elapsed)
+	}
+
+	// the refresh carries on for the next caller, without starting another
+	done := make(chan error, 1)
+	go func() { done <- client.EnsureTokenValid(context.Background()) }()
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("wanted a single refresh, got %d", n)
+	}
+}
+
 func TestFetchVideosWithExpiredToken(t *testing.T) {
 	// spin up mock servers
 	tokenSrv := httptest.NewServer(http.HandlerFunc(tokenHandler))
@@ -117,7 +158,7 @@ func TestFetchVideosWithExpiredToken(t *testing.T) {
 	)
 	client.Token = "stale-token"
 
-	videos, err := client.FetchVideos("fake-channel", 1)
+	videos, err := client.FetchVideos(context.Background(), "fake-channel", 1, model.VideoFilter{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -155,7 +196,7 @@ func TestConcurrentTokenRefresh(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			_, err := client.FetchVideos("chan", 1)
+			_, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{})
 			if err != nil {
 				t.Errorf("FetchVideos error: %v", err)
 			}
@@ -170,3 +211,677 @@ func TestConcurrentTokenRefresh(t *testing.T) {
 		t.Errorf("wanted refresh to be called once, got %d", refreshCalls)
 	}
 }
+
+func TestFetchVideosPaginates(t *testing.T) {
+	// mock video server serving 250 videos in pages of at most 100
+	const total = 250
+	var requests []string
+	var mu sync.Mutex
+
+	videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.RawQuery)
+		mu.Unlock()
+
+		first, _ := strconv.Atoi(r.URL.Query().Get("first"))
+		if first > 100 {
+			http.Error(w, "first must be <= 100", http.StatusBadRequest)
+			return
+		}
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		var resp model.VideoResponse
+		for i := offset; i < total && i < offset+first; i++ {
+			resp.Data = append(resp.Data, model.Video{Title: "Video " + strconv.Itoa(i)})
+		}
+		if next := offset + len(resp.Data); next < total {
+			resp.Pagination.Cursor = strconv.Itoa(next)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer videosSrv.Close()
+
+	tests := []struct {
+		name         string
+		limit        int
+		wantVideos   int
+		wantRequests int
+	}{
+		{name: "single page", limit: 50, wantVideos: 50, wantRequests: 1},
+		{name: "multiple pages", limit: 220, wantVideos: 220, wantRequests: 3},
+		{name: "channel runs out", limit: 400, wantVideos: total, wantRequests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+
+			client := twitch.NewTwitchAPIClient("id", "secret",
+				twitch.WithBaseURL(videosSrv.URL),
+				twitch.WithRefreshFunc(func() (string, time.Time, error) {
+					return "token", time.Now().Add(time.Minute), nil
+				}),
+			)
+
+			videos, err := client.FetchVideos(context.Background(), "chan", tt.limit, model.VideoFilter{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(videos) != tt.wantVideos {
+				t.Errorf("wanted %d videos, got %d", tt.wantVideos, len(videos))
+			}
+			if len(requests) != tt.wantRequests {
+				t.Errorf("wanted %d requests, got %d: %v", tt.wantRequests, len(requests), requests)
+			}
+			if videos[len(videos)-1].Title != "Video "+strconv.Itoa(tt.wantVideos-1) {
+				t.Errorf("videos out of order, last was %q", videos[len(videos)-1].Title)
+			}
+		})
+	}
+}
+
+func TestFetchVideosDecodesAllFields(t *testing.T) {
+	// sample payload in the shape returned by Helix
+	const payload = `{
+		"data": [{
+			"id": "335921245",
+			"stream_id": null,
+			"user_id": "141981764",
+			"user_login": "twitchdev",
+			"user_name": "TwitchDev",
+			"title": "Twitch Developers 101",
+			"description": "Welcome to Twitch development!",
+			"created_at": "2018-11-14T21:30:18Z",
+			"published_at": "2018-11-14T22:04:30Z",
+			"url": "https://www.twitch.tv/videos/335921245",
+			"thumbnail_url": "https://static-cdn.jtvnw.net/cf_vods/d1m7jfoe9zdc1j/twitchdev/335921245/thumb/thumb0-%{width}x%{height}.jpg",
+			"viewable": "public",
+			"view_count": 1863062,
+			"language": "en",
+			"type": "upload",
+			"duration": "3m21s",
+			"muted_segments": [{"duration": 30, "offset": 120}]
+		}],
+		"pagination": {}
+	}`
+
+	videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer videosSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	videos, err := client.FetchVideos(context.Background(), "141981764", 1, model.VideoFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(videos) != 1 {
+		t.Fatalf("wanted 1 video, got %d", len(videos))
+	}
+
+	v := videos[0]
+	if v.ID != "335921245" || v.UserLogin != "twitchdev" || v.URL != "https://www.twitch.tv/videos/335921245" {
+		t.Errorf("identity fields not decoded: %+v", v)
+	}
+	if v.StreamID != "" {
+		t.Errorf("wanted empty stream_id for null, got %q", v.StreamID)
+	}
+	if v.Type != model.VideoTypeUpload || v.Viewable != model.ViewablePublic || v.Language != "en" {
+		t.Errorf("enum fields not decoded: type=%q viewable=%q language=%q", v.Type, v.Viewable, v.Language)
+	}
+	if want := time.Date(2018, 11, 14, 22, 4, 30, 0, time.UTC); !v.PublishedAt.Equal(want) {
+		t.Errorf("wanted published_at %v, got %v", want, v.PublishedAt)
+	}
+	if v.CreatedAt.IsZero() || v.ThumbnailURL == "" || v.ViewCount != 1863062 {
+		t.Errorf("remaining fields not decoded: %+v", v)
+	}
+	if len(v.MutedSegments) != 1 || v.MutedSegments[0].Offset != 120 {
+		t.Errorf("muted_segments not decoded: %+v", v.MutedSegments)
+	}
+}
+
+func TestFetchVideosSendsFilter(t *testing.T) {
+	var query url.Values
+
+	videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		videosHandler(w, r)
+	}))
+	defer videosSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	filter := model.VideoFilter{
+		Type:     model.VideoTypeHighlight,
+		Period:   model.VideoPeriodMonth,
+		Sort:     model.VideoSortTrending,
+		Language: "de",
+	}
+	if _, err := client.FetchVideos(context.Background(), "chan", 5, filter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"user_id": "chan",
+		"first":   "5",
+		"type":    "highlight",
+		"period":  "month",
+		"sort":    "trending",
+	}
+	for key, val := range want {
+		if got := query.Get(key); got != val {
+			t.Errorf("wanted %s=%q, got %q", key, val, got)
+		}
+	}
+	// Helix only accepts language with game_id
+	if query.Has("language") {
+		t.Errorf("wanted no language sent, got %q", query.Get("language"))
+	}
+}
+
+func TestFetchVideosFiltersLanguage(t *testing.T) {
+	pages := map[string]model.VideoResponse{
+		"":   {Data: []model.Video{{ID: "1", Language: "de"}, {ID: "2", Language: "de"}}, Pagination: model.Pagination{Cursor: "p2"}},
+		"p2": {Data: []model.Video{{ID: "3", Language: "de"}, {ID: "4", Language: "en"}}, Pagination: model.Pagination{Cursor: "p3"}},
+		"p3": {Data: []model.Video{{ID: "5", Language: "EN"}}},
+	}
+
+	requests := 0
+	videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(pages[r.URL.Query().Get("after")])
+	}))
+	defer videosSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	// the first page has no english videos, so the client moves on to the next
+	page, err := client.FetchVideoPage(context.Background(), "chan", 2, model.VideoFilter{Language: "en"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page.Data) != 1 || page.Data[0].ID != "4" || page.Pagination.Cursor != "p3" || requests != 2 {
+		t.Errorf("wanted video 4 with cursor p3 after 2 requests, got %+v after %d", page, requests)
+	}
+
+	videos, err := client.FetchVideos(context.Background(), "chan", 5, model.VideoFilter{Language: "en"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(videos) != 2 || videos[0].ID != "4" || videos[1].ID != "5" {
+		t.Errorf("wanted english videos 4 and 5, got %+v", videos)
+	}
+}
+
+func TestFetchVideosRateLimitHeaders(t *testing.T) {
+	videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Ratelimit-Limit", "800")
+		w.Header().Set("Ratelimit-Remaining", "0")
+		w.Header().Set("Ratelimit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+		if r.URL.Query().Get("user_id") == "limited" {
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		videosHandler(w, r)
+	}))
+	defer videosSrv.Close()
+
+	limiter := twitch.NewRateLimiter(800, 0)
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithRateLimiter(limiter),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	if _, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state := client.RateLimit(); state.Limit != 800 || state.Remaining != 0 || state.Reset.IsZero() {
+		t.Errorf("wanted state from headers, got %+v", state)
+	}
+
+	// budget exhausted until reset, so the next call is rejected locally
+	if _, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{}); !errors.Is(err, twitch.ErrRateLimited) {
+		t.Errorf("wanted ErrRateLimited, got %v", err)
+	}
+
+	// a 429 from Twitch also surfaces as ErrRateLimited
+	client = twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+	if _, err := client.FetchVideos(context.Background(), "limited", 1, model.VideoFilter{}); !errors.Is(err, twitch.ErrRateLimited) {
+		t.Errorf("wanted ErrRateLimited for 429, got %v", err)
+	}
+}
+
+func TestFetchVideosRetriesTransientFailures(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		failStatus   int
+		wantErr      bool
+		wantRequests int
+	}{
+		{name: "503 blip", failures: 1, failStatus: http.StatusServiceUnavailable, wantRequests: 2},
+		{name: "429 then success", failures: 2, failStatus: http.StatusTooManyRequests, wantRequests: 3},
+		{name: "attempts exhausted", failures: 5, failStatus: http.StatusBadGateway, wantErr: true, wantRequests: 3},
+		{name: "client errors not retried", failures: 5, failStatus: http.StatusBadRequest, wantErr: true, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests <= tt.failures {
+					http.Error(w, "unavailable", tt.failStatus)
+					return
+				}
+				videosHandler(w, r)
+			}))
+			defer videosSrv.Close()
+
+			client := twitch.NewTwitchAPIClient("id", "secret",
+				twitch.WithBaseURL(videosSrv.URL),
+				twitch.WithRetryPolicy(twitch.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}),
+				twitch.WithRefreshFunc(func() (string, time.Time, error) {
+					return "token", time.Now().Add(time.Minute), nil
+				}),
+			)
+
+			videos, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{})
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("wanted error=%v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && len(videos) != 1 {
+				t.Errorf("wanted 1 video after retry, got %d", len(videos))
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("wanted %d requests, got %d", tt.wantRequests, requests)
+			}
+		})
+	}
+}
+
+func TestFetchVideosRetriesNetworkErrors(t *testing.T) {
+	// closed server refuses connections
+	videosSrv := httptest.NewServer(http.HandlerFunc(videosHandler))
+	videosSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithRetryPolicy(twitch.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		twitch.WithRateLimiter(twitch.NewRateLimiter(6, time.Second)), // refills one token per 10s
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	if _, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{}); err == nil {
+		t.Fatalf("wanted network error, got nil")
+	}
+	if state := client.RateLimit(); state.Remaining != 4 {
+		t.Errorf("wanted 2 attempts to take 2 tokens, got %+v", state)
+	}
+}
+
+func TestFetchVideosRefreshesRevokedToken(t *testing.T) {
+	tests := []struct {
+		name         string
+		acceptToken  string // token the server accepts; anything else gets 401
+		wantErr      bool
+		wantRefresh  int
+		wantRequests int
+	}{
+		{name: "revoked token replaced", acceptToken: "fresh-token", wantRefresh: 1, wantRequests: 2},
+		{name: "refreshed token also rejected", acceptToken: "never", wantErr: true, wantRefresh: 1, wantRequests: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.Header.Get("Authorization") != "Bearer "+tt.acceptToken {
+					w.WriteHeader(http.StatusUnauthorized)
+					_, _ = w.Write([]byte(`{"error":"Unauthorized","status":401,"message":"Invalid OAuth token"}`))
+					return
+				}
+				videosHandler(w, r)
+			}))
+			defer videosSrv.Close()
+
+			var refreshCalls int
+			client := twitch.NewTwitchAPIClient("id", "secret",
+				twitch.WithBaseURL(videosSrv.URL),
+				twitch.WithRefreshFunc(func() (string, time.Time, error) {
+					refreshCalls++
+					return "fresh-token", time.Now().Add(time.Hour), nil
+				}),
+				twitch.WithExpires(time.Now().Add(time.Hour)), // locally still valid
+			)
+			client.Token = "revoked-token"
+
+			_, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wanted error %v, got %v", tt.wantErr, err)
+			}
+			if refreshCalls != tt.wantRefresh {
+				t.Errorf("wanted %d refreshes, got %d", tt.wantRefresh, refreshCalls)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("wanted %d requests, got %d", tt.wantRequests, requests)
+			}
+		})
+	}
+}
+
+func TestConcurrentRevokedTokenRefresh(t *testing.T) {
+	var refreshCalls int
+	var mu sync.Mutex
+
+	refresh := func() (string, time.Time, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		refreshCalls++
+		return "fresh-token", time.Now().Add(time.Hour), nil
+	}
+
+	videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh-token" {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		videosHandler(w, r)
+	}))
+	defer videosSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithRefreshFunc(refresh),
+		twitch.WithExpires(time.Now().Add(time.Hour)),
+	)
+	client.Token = "revoked-token"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{}); err != nil {
+				t.Errorf("FetchVideos error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if refreshCalls != 1 {
+		t.Errorf("wanted refresh to be called once, got %d", refreshCalls)
+	}
+}
+
+func TestFetchVideosFetchesTokenFromAuthURL(t *testing.T) {
+	var tokenCalls int
+	authSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls++
+		if tokenCalls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable) // transient failure is retried
+			return
+		}
+		if r.URL.Path != "/token" {
+			t.Errorf("wanted /token, got %s", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("parse form: %v", err)
+		}
+		if got := r.PostForm.Get("client_secret"); got != "s&cret=1" {
+			t.Errorf("wanted url encoded secret, got %q", got)
+		}
+		if got := r.PostForm.Get("grant_type"); got != "client_credentials" {
+			t.Errorf("wanted client_credentials grant, got %q", got)
+		}
+		tokenHandler(w, r)
+	}))
+	defer authSrv.Close()
+
+	videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer mock-token" {
+			t.Errorf("wanted fetched token, got %q", got)
+		}
+		videosHandler(w, r)
+	}))
+	defer videosSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "s&cret=1",
+		twitch.WithAuthURL(authSrv.URL),
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithRetryPolicy(twitch.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	)
+
+	if _, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tokenCalls != 2 {
+		t.Errorf("wanted 2 token calls, got %d", tokenCalls)
+	}
+}
+
+func TestFetchVideosTimeout(t *testing.T) {
+	videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		videosHandler(w, r)
+	}))
+	defer videosSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithTimeout(10*time.Millisecond),
+		twitch.WithRetryPolicy(twitch.RetryPolicy{MaxAttempts: 1}),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	if _, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{}); err == nil {
+		t.Fatal("wanted timeout error, got nil")
+	}
+}
+
+func TestFetchTokenTimeoutWithInjectedClient(t *testing.T) {
+	release := make(chan struct{})
+	authSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer authSrv.Close()
+	defer close(release)
+
+	// an injected client without a timeout still has the token request bounded
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithAuthURL(authSrv.URL),
+		twitch.WithHTTPClient(&http.Client{}),
+		twitch.WithCallTimeout(50*time.Millisecond),
+		twitch.WithRetryPolicy(twitch.RetryPolicy{MaxAttempts: 1}),
+	)
+
+	done := make(chan error, 1)
+	go func() { done <- client.EnsureTokenValid(context.Background()) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("wanted context.DeadlineExceeded, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("token request was not bounded")
+	}
+}
+
+func TestFetchVideosUsesProxy(t *testing.T) {
+	// plain HTTP requests through a proxy carry the absolute target URL
+	var proxiedHost string
+	proxySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.URL.Host
+		videosHandler(w, r)
+	}))
+	defer proxySrv.Close()
+
+	proxyURL, _ := url.Parse(proxySrv.URL)
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL("http://helix.staging.invalid/videos"),
+		twitch.WithProxy(proxyURL),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	if _, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proxiedHost != "helix.staging.invalid" {
+		t.Errorf("wanted request for helix.staging.invalid via proxy, got %q", proxiedHost)
+	}
+}
+
+func TestFetchVideosCallTimeout(t *testing.T) {
+	var requests int
+	videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer videosSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithCallTimeout(50*time.Millisecond),
+		twitch.WithRetryPolicy(twitch.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second}),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	start := time.Now()
+	_, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wanted context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("wanted backoff cut short by the deadline, took %v", elapsed)
+	}
+	if requests != 1 {
+		t.Errorf("wanted 1 request before the deadline, got %d", requests)
+	}
+}
+
+func TestFetchVideosCancelledContext(t *testing.T) {
+	videosSrv := httptest.NewServer(http.HandlerFunc(videosHandler))
+	defer videosSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.FetchVideos(ctx, "chan", 1, model.VideoFilter{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted context.Canceled, got %v", err)
+	}
+}
+
+func TestFetchVideosTypedErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		header      map[string]string
+		body        string
+		wantIs      error
+		wantMessage string
+		wantRetry   time.Duration
+	}{
+		{name: "not found", status: 404, body: `{"error":"Not Found","status":404,"message":"user not found"}`, wantIs: twitch.ErrChannelNotFound, wantMessage: "user not found"},
+		{name: "rate limited", status: 429, header: map[string]string{"Retry-After": "30"}, wantIs: twitch.ErrRateLimited, wantRetry: 30 * time.Second},
+		{name: "unavailable", status: 503, body: `{"error":"Service Unavailable","status":503,"message":""}`, wantIs: twitch.ErrUpstreamUnavailable},
+		{name: "bad request", status: 400, body: `{"error":"Bad Request","status":400,"message":"Malformed query params."}`, wantMessage: "Malformed query params."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			videosSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer videosSrv.Close()
+
+			client := twitch.NewTwitchAPIClient("id", "secret",
+				twitch.WithBaseURL(videosSrv.URL),
+				twitch.WithRetryPolicy(twitch.RetryPolicy{MaxAttempts: 1}),
+				twitch.WithRefreshFunc(func() (string, time.Time, error) {
+					return "token", time.Now().Add(time.Minute), nil
+				}),
+			)
+
+			_, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{})
+
+			var apiErr *twitch.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("wanted *twitch.APIError, got %v", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Message != tt.wantMessage || apiErr.RetryAfter() != tt.wantRetry {
+				t.Errorf("wanted status %d message %q retry %v, got %+v retry %v", tt.status, tt.wantMessage, tt.wantRetry, apiErr, apiErr.RetryAfter())
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("wanted error to match %v", tt.wantIs)
+			}
+		})
+	}
+}
+
+func TestFetchVideosUnreachable(t *testing.T) {
+	videosSrv := httptest.NewServer(http.HandlerFunc(videosHandler))
+	videosSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithBaseURL(videosSrv.URL),
+		twitch.WithRetryPolicy(twitch.RetryPolicy{MaxAttempts: 1}),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	if _, err := client.FetchVideos(context.Background(), "chan", 1, model.VideoFilter{}); !errors.Is(err, twitch.ErrUpstreamUnavailable) {
+		t.Errorf("wanted ErrUpstreamUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package twitch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrChannelNotFound is returned when a channel or user does not exist on Twitch.
+	ErrChannelNotFound = errors.New("channel not found")
+
+	// ErrRateLimited is returned when the Helix rate budget is exhausted, either
+	// locally or by Twitch responding 429.
+	ErrRateLimited = errors.New("twitch rate limit exceeded")
+
+	// ErrUpstreamUnavailable is returned when Twitch cannot be reached or fails
+	// with a 5xx after retries.
+	ErrUpstreamUnavailable = errors.New("twitch unavailable")
+)
+
+// APIError non-success response from Twitch. Reason and Message are decoded from
+// the Helix error body ({"error": ..., "status": ..., "message": ...}) when
+// present. It matches ErrChannelNotFound for 404, ErrRateLimited for 429 and
+// ErrUpstreamUnavailable for 5xx with errors.Is.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Reason     string `json:"error"`
+	Message    string `json:"message"`
+
+	retryAfter time.Duration
+}
+
+// newAPIError builds an APIError from resp, reading its body and retry hint
+func newAPIError(resp *http.Response, now time.Time) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = json.Unmarshal(body, e)
+
+	e.retryAfter, _ = retryHint(resp, now)
+	return e
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("twitch API returned %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("twitch API returned %d", e.StatusCode)
+}
+
+// Is maps the response status to the package's sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrChannelNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrUpstreamUnavailable:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// RetryAfter returns how long Twitch asked callers to wait, zero if it did not say.
+func (e *APIError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// rateLimitError rejection by the local rate limiter, carrying how long the
+// caller would have had to wait for budget
+type rateLimitError struct {
+	wait time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return ErrRateLimited.Error()
+}
+
+func (e *rateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RetryAfter returns how long until budget is available again
+func (e *rateLimitError) RetryAfter() time.Duration {
+	return time.Duration(math.Ceil(e.wait.Seconds())) * time.Second
+}
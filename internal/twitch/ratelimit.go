@@ -0,0 +1,157 @@
+package twitch
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRateLimit is the Helix app token budget per minute
+	defaultRateLimit = 800
+
+	// defaultMaxWait is how long a call queues for budget before being rejected
+	defaultMaxWait = 5 * time.Second
+)
+
+// RateLimitState snapshot of a RateLimiter's budget
+type RateLimitState struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// RateLimiter token bucket shared by every Helix call made through the clients
+// using it. The bucket refills at Limit tokens per minute and is corrected from
+// the Ratelimit-Limit, Ratelimit-Remaining and Ratelimit-Reset response headers.
+// Calls queue for a token for up to maxWait and are rejected with ErrRateLimited
+// beyond that.
+type RateLimiter struct {
+	limit        int
+	tokens       float64
+	last         time.Time
+	reset        time.Time
+	blockedUntil time.Time
+	maxWait      time.Duration
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+
+	mu sync.Mutex
+}
+
+// NewRateLimiter creates a RateLimiter with a full bucket of limit tokens per
+// minute, queueing calls for at most maxWait.
+func NewRateLimiter(limit int, maxWait time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		tokens:  float64(limit),
+		last:    time.Now(),
+		maxWait: maxWait,
+		now:     time.Now,
+		sleep:   sleepContext,
+	}
+}
+
+// Wait takes a token, blocking until one is available. ErrRateLimited is
+// returned without taking a token if that would take longer than maxWait or
+// outlast ctx's deadline. If ctx is cancelled while waiting the token is given
+// back and ctx's error returned.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+
+	now := l.now()
+	l.refill(now)
+
+	var wait time.Duration
+	if l.tokens < 1 {
+		wait = time.Duration((1 - l.tokens) / l.rate() * float64(time.Second))
+	}
+	if blocked := l.blockedUntil.Sub(now); blocked > wait {
+		wait = blocked
+	}
+
+	if deadline, ok := ctx.Deadline(); wait > l.maxWait || ok && now.Add(wait).After(deadline) {
+		l.mu.Unlock()
+		return &rateLimitError{wait: wait}
+	}
+
+	// reserve the token now so later callers queue behind this one
+	l.tokens--
+	l.mu.Unlock()
+
+	if wait > 0 {
+		if err := l.sleep(ctx, wait); err != nil {
+			l.mu.Lock()
+			l.tokens++
+			l.mu.Unlock()
+			return err
+		}
+	}
+	return nil
+}
+
+// Update corrects the bucket from Helix Ratelimit-* response headers. Missing or
+// malformed headers are ignored.
+func (l *RateLimiter) Update(header http.Header) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.refill(now)
+
+	if limit, err := strconv.Atoi(header.Get("Ratelimit-Limit")); err == nil && limit > 0 {
+		l.limit = limit
+	}
+	if reset, err := strconv.ParseInt(header.Get("Ratelimit-Reset"), 10, 64); err == nil {
+		l.reset = time.Unix(reset, 0)
+	}
+	if remaining, err := strconv.Atoi(header.Get("Ratelimit-Remaining")); err == nil {
+		l.tokens = float64(remaining)
+		if remaining == 0 && l.reset.After(now) {
+			l.blockedUntil = l.reset
+		}
+	}
+}
+
+// State returns the current budget.
+func (l *RateLimiter) State() RateLimitState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(l.now())
+
+	return RateLimitState{
+		Limit:     l.limit,
+		Remaining: max(int(l.tokens), 0),
+		Reset:     l.reset,
+	}
+}
+
+// refill adds the tokens accrued since the last refill, capped at the limit
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+		l.tokens = min(l.tokens+elapsed*l.rate(), float64(l.limit))
+		l.last = now
+	}
+}
+
+// rate returns the refill rate in tokens per second
+func (l *RateLimiter) rate() float64 {
+	return float64(l.limit) / 60
+}
+
+// sleepContext sleeps for d, returning early with ctx's error if it is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
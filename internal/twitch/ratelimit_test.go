@@ -0,0 +1,130 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestLimiter returns a limiter on a fake clock that advances when it sleeps
+func newTestLimiter(limit int, maxWait time.Duration) (*RateLimiter, *time.Time, *[]time.Duration) {
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	var sleeps []time.Duration
+
+	l := NewRateLimiter(limit, maxWait)
+	l.now = func() time.Time { return now }
+	l.sleep = func(_ context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		now = now.Add(d)
+		return nil
+	}
+	l.last = now
+
+	return l, &now, &sleeps
+}
+
+func TestRateLimiterQueuesWhenEmpty(t *testing.T) {
+	// 60 per minute refills one token per second
+	l, _, sleeps := newTestLimiter(60, 5*time.Second)
+	l.tokens = 1
+
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(*sleeps) != 2 || (*sleeps)[0] != time.Second || (*sleeps)[1] != time.Second {
+		t.Errorf("wanted two 1s waits after the first token, got %v", *sleeps)
+	}
+}
+
+func TestRateLimiterRejectsBeyondMaxWait(t *testing.T) {
+	l, _, sleeps := newTestLimiter(60, 500*time.Millisecond)
+	l.tokens = 0
+
+	if err := l.Wait(context.Background()); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("wanted ErrRateLimited, got %v", err)
+	}
+	if len(*sleeps) != 0 {
+		t.Errorf("wanted no wait when rejecting, got %v", *sleeps)
+	}
+	if l.State().Remaining != 0 {
+		t.Errorf("wanted rejected call not to take a token, got %+v", l.State())
+	}
+}
+
+func TestRateLimiterUpdateFromHeaders(t *testing.T) {
+	l, now, sleeps := newTestLimiter(800, 10*time.Second)
+
+	reset := now.Add(3 * time.Second).Truncate(time.Second)
+	header := http.Header{}
+	header.Set("Ratelimit-Limit", "120")
+	header.Set("Ratelimit-Remaining", "0")
+	header.Set("Ratelimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	l.Update(header)
+
+	state := l.State()
+	if state.Limit != 120 || state.Remaining != 0 || !state.Reset.Equal(reset) {
+		t.Errorf("wanted state from headers, got %+v", state)
+	}
+
+	// exhausted budget waits until reset
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*sleeps) != 1 || (*sleeps)[0] != 3*time.Second {
+		t.Errorf("wanted a 3s wait until reset, got %v", *sleeps)
+	}
+}
+
+func TestRateLimiterRefillCapsAtLimit(t *testing.T) {
+	l, now, _ := newTestLimiter(60, time.Second)
+	l.tokens = 0
+
+	*now = now.Add(time.Hour)
+	if got := l.State().Remaining; got != 60 {
+		t.Errorf("wanted refill capped at 60, got %d", got)
+	}
+}
+
+func TestRateLimiterRespectsContext(t *testing.T) {
+	// 60 per minute refills one token per second
+	l, now, sleeps := newTestLimiter(60, 5*time.Second)
+	l.tokens = 0
+
+	// a deadline sooner than the wait is rejected up front
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(500*time.Millisecond))
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("wanted ErrRateLimited past the deadline, got %v", err)
+	}
+	if len(*sleeps) != 0 {
+		t.Errorf("wanted no wait when rejecting, got %v", *sleeps)
+	}
+
+	// cancelling while queued gives the token back
+	l.sleep = func(ctx context.Context, d time.Duration) error { return context.Canceled }
+	if err := l.Wait(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Errorf("wanted context.Canceled, got %v", err)
+	}
+	if l.tokens != 0 {
+		t.Errorf("wanted cancelled wait to return its token, got %v tokens", l.tokens)
+	}
+}
+
+func TestRateLimiterRejectionRetryAfter(t *testing.T) {
+	// 60 per minute refills one token per second
+	l, _, _ := newTestLimiter(60, 500*time.Millisecond)
+	l.tokens = -1
+
+	err := l.Wait(context.Background())
+
+	var hint interface{ RetryAfter() time.Duration }
+	if !errors.As(err, &hint) || hint.RetryAfter() != 2*time.Second {
+		t.Errorf("wanted rejection to suggest retrying after 2s, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how transient failures are retried: network errors, 429
+// and 5xx responses. Delays grow exponentially from BaseDelay, are capped at
+// MaxDelay and are randomized by up to Jitter (a fraction of the delay). A
+// Retry-After or Ratelimit-Reset hint from Twitch replaces the computed delay;
+// if the hint exceeds MaxDelay the response is returned without retrying.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Jitter      float64
+}
+
+// DefaultRetryPolicy retries up to three attempts over roughly a second.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   250 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      0.2,
+}
+
+// WithRetryPolicy sets the retry policy applied to every Helix and token call.
+func WithRetryPolicy(p RetryPolicy) func(*TwitchAPIClient) {
+	return func(c *TwitchAPIClient) { c.retry = p }
+}
+
+// doWithRetry calls send until it succeeds, fails permanently or the policy's
+// attempts are exhausted, sleeping between attempts. The final response is
+// returned as is for the caller to handle its status. Errors wrapping
+// ErrRateLimited come from the local limiter and are not retried, and nothing is
+// retried once ctx is done.
+func (c *TwitchAPIClient) doWithRetry(ctx context.Context, send func() (*http.Response, error)) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := send()
+
+		last := attempt >= c.retry.MaxAttempts
+		if err != nil {
+			if last || errors.Is(err, ErrRateLimited) || ctx.Err() != nil {
+				return nil, err
+			}
+			if err := c.sleep(ctx, c.retry.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !retryable(resp.StatusCode) || last {
+			return resp, nil
+		}
+
+		delay, ok := c.retry.delay(resp, attempt, c.now())
+		if !ok {
+			return resp, nil
+		}
+
+		// drain so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable reports whether a response status is worth retrying
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// delay returns how long to wait before retrying resp, preferring the server's
+// Retry-After or Ratelimit-Reset hint over exponential backoff. It reports false
+// when the server asks for a longer wait than MaxDelay.
+func (p RetryPolicy) delay(resp *http.Response, attempt int, now time.Time) (time.Duration, bool) {
+	hint, ok := retryHint(resp, now)
+	if !ok {
+		return p.backoff(attempt), true
+	}
+	return hint, hint <= p.MaxDelay
+}
+
+// retryHint returns the wait Twitch asked for in resp's Retry-After header, or
+// until Ratelimit-Reset for a 429, reporting false when neither is present.
+func retryHint(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("Ratelimit-Reset"), 10, 64); err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		return max(time.Unix(reset, 0).Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// backoff returns the jittered exponential delay before the next attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d > p.MaxDelay || d <= 0 {
+		d = p.MaxDelay
+	}
+
+	if p.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+	}
+	return d
+}
@@ -0,0 +1,84 @@
+package twitch
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 4, want: 800 * time.Millisecond},
+		{attempt: 5, want: time.Second},
+		{attempt: 70, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := p.backoff(tt.attempt); got != tt.want {
+			t.Errorf("attempt %d: wanted %v, got %v", tt.attempt, tt.want, got)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffJitter(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Second, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(1); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("wanted delay within 50%% of 1s, got %v", got)
+		}
+	}
+}
+
+func TestRetryPolicyDelayHints(t *testing.T) {
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		want    time.Duration
+		wantOK  bool
+	}{
+		{name: "no hint uses backoff", status: 503, want: 100 * time.Millisecond, wantOK: true},
+		{name: "retry after", status: 503, headers: map[string]string{"Retry-After": "2"}, want: 2 * time.Second, wantOK: true},
+		{name: "retry after too long", status: 503, headers: map[string]string{"Retry-After": "60"}, want: time.Minute, wantOK: false},
+		{
+			name:    "ratelimit reset on 429",
+			status:  429,
+			headers: map[string]string{"Ratelimit-Reset": strconv.FormatInt(now.Add(3*time.Second).Unix(), 10)},
+			want:    3 * time.Second,
+			wantOK:  true,
+		},
+		{
+			name:    "ratelimit reset ignored on 5xx",
+			status:  502,
+			headers: map[string]string{"Ratelimit-Reset": strconv.FormatInt(now.Add(time.Minute).Unix(), 10)},
+			want:    100 * time.Millisecond,
+			wantOK:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for k, v := range tt.headers {
+				resp.Header.Set(k, v)
+			}
+
+			got, ok := p.delay(resp, 1, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("wanted (%v, %v), got (%v, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package twitch
+
+import (
+	"context"
+	"fourthfloor/internal/model"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultUsersTTL is how long resolved users are cached by default. Logins can
+// be renamed, so entries expire rather than living for the process lifetime.
+const defaultUsersTTL = time.Hour
+
+// defaultMaxUsers is how many resolved users are cached by default
+const defaultMaxUsers = 1000
+
+// cachedUser user lookup result with its expiry
+type cachedUser struct {
+	user    model.User
+	expires time.Time
+}
+
+// FetchUserByLogin resolves a login name to its Twitch user, ensuring a valid
+// token first. Results are cached per login, up to a bounded number of logins;
+// ErrChannelNotFound is returned when no user has that login.
+func (c *TwitchAPIClient) FetchUserByLogin(ctx context.Context, login string) (model.User, error) {
+	login = strings.ToLower(login)
+
+	c.usersMu.Lock()
+	cached, ok := c.users[login]
+	c.usersMu.Unlock()
+	if ok && c.now().Before(cached.expires) {
+		return cached.user, nil
+	}
+
+	if err := c.EnsureTokenValid(ctx); err != nil {
+		return model.User{}, err
+	}
+
+	log.Printf("Fetching user")
+
+	query := url.Values{}
+	query.Set("login", login)
+
+	var result model.UserResponse
+	if err := c.helixGet(ctx, c.UsersURL, query, &result); err != nil {
+		return model.User{}, err
+	}
+	if len(result.Data) == 0 {
+		return model.User{}, ErrChannelNotFound
+	}
+
+	c.usersMu.Lock()
+	c.cacheUser(login, result.Data[0])
+	c.usersMu.Unlock()
+
+	return result.Data[0], nil
+}
+
+// cacheUser caches user under login, first dropping expired entries when the
+// cache is full and then, if still full, the entry closest to expiry. The
+// caller holds usersMu.
+func (c *TwitchAPIClient) cacheUser(login string, user model.User) {
+	now := c.now()
+
+	if _, ok := c.users[login]; !ok && len(c.users) >= c.maxUsers {
+		var oldest string
+		for l, u := range c.users {
+			if !now.Before(u.expires) {
+				delete(c.users, l)
+			} else if oldest == "" || u.expires.Before(c.users[oldest].expires) {
+				oldest = l
+			}
+		}
+		if len(c.users) >= c.maxUsers && oldest != "" {
+			delete(c.users, oldest)
+		}
+	}
+
+	if c.maxUsers > 0 {
+		c.users[login] = cachedUser{user: user, expires: now.Add(c.usersTTL)}
+	}
+}
@@ -0,0 +1,140 @@
+package twitch_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"fourthfloor/internal/model"
+	"fourthfloor/internal/twitch"
+)
+
+// ---- Mocks ----
+
+// usersHandler mock users server response, counting requests
+func usersHandler(requests *int, mu *sync.Mutex) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*requests++
+		mu.Unlock()
+
+		var resp model.UserResponse
+		if r.URL.Query().Get("login") == "twitchdev" {
+			resp.Data = []model.User{{
+				ID:              "141981764",
+				Login:           "twitchdev",
+				DisplayName:     "TwitchDev",
+				BroadcasterType: model.BroadcasterTypePartner,
+				ProfileImageURL: "https://static-cdn.jtvnw.net/profile.png",
+				CreatedAt:       time.Date(2016, 12, 14, 20, 32, 28, 0, time.UTC),
+			}}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// ---- Tests ----
+
+func TestFetchUserByLogin(t *testing.T) {
+	var requests int
+	var mu sync.Mutex
+
+	usersSrv := httptest.NewServer(usersHandler(&requests, &mu))
+	defer usersSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithUsersURL(usersSrv.URL),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	user, err := client.FetchUserByLogin(context.Background(), "TwitchDev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.ID != "141981764" || user.BroadcasterType != model.BroadcasterTypePartner || user.CreatedAt.IsZero() {
+		t.Errorf("wanted twitchdev profile, got %+v", user)
+	}
+
+	// second lookup is served from the cache
+	if _, err := client.FetchUserByLogin(context.Background(), "twitchdev"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("wanted 1 request with caching, got %d", requests)
+	}
+
+	if _, err := client.FetchUserByLogin(context.Background(), "nobody"); !errors.Is(err, twitch.ErrChannelNotFound) {
+		t.Errorf("wanted ErrChannelNotFound, got %v", err)
+	}
+}
+
+func TestFetchUserByLoginCacheExpires(t *testing.T) {
+	var requests int
+	var mu sync.Mutex
+
+	usersSrv := httptest.NewServer(usersHandler(&requests, &mu))
+	defer usersSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithUsersURL(usersSrv.URL),
+		twitch.WithUsersTTL(-time.Second), // entries expire immediately
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.FetchUserByLogin(context.Background(), "twitchdev"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("wanted 2 requests once cache expired, got %d", requests)
+	}
+}
+
+func TestFetchUserByLoginCacheBounded(t *testing.T) {
+	requests := map[string]int{}
+	var mu sync.Mutex
+
+	usersSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		login := r.URL.Query().Get("login")
+		mu.Lock()
+		requests[login]++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(model.UserResponse{Data: []model.User{{ID: login, Login: login}}})
+	}))
+	defer usersSrv.Close()
+
+	client := twitch.NewTwitchAPIClient("id", "secret",
+		twitch.WithUsersURL(usersSrv.URL),
+		twitch.WithMaxUsers(2),
+		twitch.WithRefreshFunc(func() (string, time.Time, error) {
+			return "token", time.Now().Add(time.Minute), nil
+		}),
+	)
+
+	// c evicts a, the entry closest to expiry
+	for _, login := range []string{"a", "b", "c", "b", "c", "a"} {
+		if _, err := client.FetchUserByLogin(context.Background(), login); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests["a"] != 2 || requests["b"] != 1 || requests["c"] != 1 {
+		t.Errorf("wanted a evicted and fetched again, b and c cached, got %v", requests)
+	}
+}
@@ -0,0 +1,113 @@
+package twitch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultValidateInterval is how often Twitch requires apps to validate tokens.
+const DefaultValidateInterval = time.Hour
+
+// StartValidator validates the token immediately and then every interval until
+// ctx is cancelled or StopValidator is called. Calling it while a validator is
+// already running has no effect.
+func (c *TwitchAPIClient) StartValidator(ctx context.Context, interval time.Duration) {
+	c.validatorMu.Lock()
+	defer c.validatorMu.Unlock()
+
+	if c.stopValidator != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := c.ValidateToken(ctx); err != nil {
+				log.Printf("token validation failed: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	c.stopValidator = func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// StopValidator stops the background validator and waits for it to exit.
+func (c *TwitchAPIClient) StopValidator() {
+	c.validatorMu.Lock()
+	stop := c.stopValidator
+	c.stopValidator = nil
+	c.validatorMu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+// ValidateToken checks the current token against the OAuth validate endpoint.
+// A valid token has its expiry updated from the response; a token Twitch no
+// longer accepts is refreshed. Without a token one is fetched instead.
+func (c *TwitchAPIClient) ValidateToken(ctx context.Context) error {
+	token := c.currentToken()
+	if token == "" {
+		return c.EnsureTokenValid(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.callTimeout)
+	defer cancel()
+
+	resp, err := c.doWithRetry(ctx, func() (*http.Response, error) {
+		req, _ := http.NewRequestWithContext(ctx, "GET", c.AuthURL+"/validate", nil)
+		req.Header.Set("Authorization", "OAuth "+token)
+		return c.httpClient.Do(req)
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return c.forceRefresh(ctx, token)
+	default:
+		return fmt.Errorf("token validation returned %d", resp.StatusCode)
+	}
+
+	var v struct {
+		ExpiresIn int `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// the token may have been refreshed while validating
+	if c.Token == token {
+		c.expires = c.now().Add(time.Duration(v.ExpiresIn) * time.Second)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package twitch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestValidateToken(t *testing.T) {
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantErr     bool
+		wantToken   string
+		wantExpires time.Time
+	}{
+		{
+			name:        "valid token extends expiry",
+			status:      http.StatusOK,
+			body:        `{"client_id":"id","scopes":[],"expires_in":3600}`,
+			wantToken:   "old-token",
+			wantExpires: now.Add(time.Hour),
+		},
+		{
+			name:        "invalid token refreshed",
+			status:      http.StatusUnauthorized,
+			body:        `{"status":401,"message":"invalid access token"}`,
+			wantToken:   "new-token",
+			wantExpires: now.Add(2 * time.Hour),
+		},
+		{
+			name:        "rejected validation leaves token alone",
+			status:      http.StatusBadRequest,
+			wantErr:     true,
+			wantToken:   "old-token",
+			wantExpires: now.Add(time.Minute),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/validate" || r.Header.Get("Authorization") != "OAuth old-token" {
+					t.Errorf("unexpected request %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer authSrv.Close()
+
+			c := NewTwitchAPIClient("id", "secret",
+				WithAuthURL(authSrv.URL),
+				WithExpires(now.Add(time.Minute)),
+				WithRefreshFunc(func() (string, time.Time, error) {
+					return "new-token", now.Add(2 * time.Hour), nil
+				}),
+			)
+			c.Token = "old-token"
+			c.now = func() time.Time { return now }
+
+			err := c.ValidateToken(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("wanted error %v, got %v", tt.wantErr, err)
+			}
+			if c.Token != tt.wantToken {
+				t.Errorf("wanted token %q, got %q", tt.wantToken, c.Token)
+			}
+			if !c.expires.Equal(tt.wantExpires) {
+				t.Errorf("wanted expiry %v, got %v", tt.wantExpires, c.expires)
+			}
+		})
+	}
+}
+
+func TestValidatorStartStop(t *testing.T) {
+	var calls atomic.Int32
+	authSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{"expires_in":3600}`))
+	}))
+	defer authSrv.Close()
+
+	c := NewTwitchAPIClient("id", "secret", WithAuthURL(authSrv.URL))
+	c.Token = "token"
+
+	c.StartValidator(context.Background(), 10*time.Millisecond)
+	c.StartValidator(context.Background(), 10*time.Millisecond) // no second validator
+
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	c.StopValidator()
+
+	stopped := calls.Load()
+	if stopped < 3 {
+		t.Fatalf("wanted periodic validation, got %d calls", stopped)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if got := calls.Load(); got != stopped {
+		t.Errorf("wanted no validation after stop, got %d more calls", got-stopped)
+	}
+}
+
+func TestValidatorStopsOnContextCancel(t *testing.T) {
+	authSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"expires_in":3600}`))
+	}))
+	defer authSrv.Close()
+
+	c := NewTwitchAPIClient("id", "secret", WithAuthURL(authSrv.URL))
+	c.Token = "token"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.StartValidator(ctx, time.Hour)
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		c.StopValidator()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("validator did not exit after context cancel")
+	}
+}
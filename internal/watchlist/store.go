@@ -0,0 +1,221 @@
+package watchlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fourthfloor/internal/model"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MinInterval is the shortest poll interval an entry may ask for
+const MinInterval = time.Minute
+
+var (
+	// ErrNotWatched is returned for a channel that is not on the watchlist.
+	ErrNotWatched = errors.New("channel is not on the watchlist")
+
+	// ErrAlreadyWatched is returned when adding a channel already on the watchlist.
+	ErrAlreadyWatched = errors.New("channel is already on the watchlist")
+)
+
+// Store watchlist kept in memory and persisted to a JSON file, which is
+// rewritten atomically on every change so that a crash never leaves it half
+// written. A change that cannot be persisted is not applied.
+type Store struct {
+	path string
+	now  func() time.Time
+
+	mu        sync.Mutex // protects entries and listeners
+	entries   map[string]model.WatchlistEntry
+	listeners []func([]model.WatchlistEntry)
+}
+
+// Open loads the watchlist persisted at path, starting empty if the file does
+// not exist yet.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, now: time.Now, entries: make(map[string]model.WatchlistEntry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open watchlist %s: %w", path, err)
+	}
+
+	var entries []model.WatchlistEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("open watchlist %s: %w", path, err)
+	}
+	for _, e := range entries {
+		s.entries[e.ChannelID] = e
+	}
+	return s, nil
+}
+
+// OnChange registers fn to be called with the whole watchlist after every
+// change. It is called synchronously and must not call back into the Store.
+func (s *Store) OnChange(fn func([]model.WatchlistEntry)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// List returns every entry ordered by channel id.
+func (s *Store) List() []model.WatchlistEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sorted(s.entries)
+}
+
+// Get returns the entry for a channel.
+func (s *Store) Get(channelID string) (model.WatchlistEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[channelID]
+	if !ok {
+		return model.WatchlistEntry{}, ErrNotWatched
+	}
+	return e, nil
+}
+
+// Add adds an entry, returning it as stored.
+func (s *Store) Add(e model.WatchlistEntry) (model.WatchlistEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[e.ChannelID]; ok {
+		return model.WatchlistEntry{}, ErrAlreadyWatched
+	}
+
+	e.Tags = normalizeTags(e.Tags)
+	e.AddedAt = s.now().UTC()
+	e.UpdatedAt = e.AddedAt
+	return e, s.apply(e.ChannelID, &e)
+}
+
+// Update applies patch to a channel's entry, returning it as stored.
+func (s *Store) Update(channelID string, patch model.WatchlistPatch) (model.WatchlistEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[channelID]
+	if !ok {
+		return model.WatchlistEntry{}, ErrNotWatched
+	}
+
+	if patch.IntervalSeconds != nil {
+		e.IntervalSeconds = *patch.IntervalSeconds
+	}
+	if patch.Tags != nil {
+		e.Tags = normalizeTags(*patch.Tags)
+	}
+	if patch.Notes != nil {
+		e.Notes = *patch.Notes
+	}
+	e.UpdatedAt = s.now().UTC()
+	return e, s.apply(channelID, &e)
+}
+
+// Remove removes a channel's entry.
+func (s *Store) Remove(channelID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[channelID]; !ok {
+		return ErrNotWatched
+	}
+	return s.apply(channelID, nil)
+}
+
+// apply persists the watchlist with channelID set to e, or removed if e is
+// nil, then swaps it in and notifies listeners. s.mu must be held.
+func (s *Store) apply(channelID string, e *model.WatchlistEntry) error {
+	entries := make(map[string]model.WatchlistEntry, len(s.entries)+1)
+	for id, existing := range s.entries {
+		entries[id] = existing
+	}
+	if e != nil {
+		entries[channelID] = *e
+	} else {
+		delete(entries, channelID)
+	}
+
+	list := sorted(entries)
+	if err := s.save(list); err != nil {
+		return err
+	}
+	s.entries = entries
+
+	for _, fn := range s.listeners {
+		fn(slices.Clone(list))
+	}
+	return nil
+}
+
+// save writes entries to a temporary file beside the watchlist, syncs it and
+// renames it over the watchlist
+func (s *Store) save(entries []model.WatchlistEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("save watchlist: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("save watchlist: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("save watchlist: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("save watchlist: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("save watchlist: %w", err)
+	}
+	return nil
+}
+
+// sorted returns entries ordered by channel id
+func sorted(entries map[string]model.WatchlistEntry) []model.WatchlistEntry {
+	list := make([]model.WatchlistEntry, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ChannelID < list[j].ChannelID })
+	return list
+}
+
+// NormalizeTag returns tag as stored on an entry: trimmed and lowercased
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags trims and lowercases tags, dropping empty and duplicate ones
+func normalizeTags(tags []string) []string {
+	var out []string
+	for _, t := range tags {
+		t = NormalizeTag(t)
+		if t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,112 @@
+package watchlist
+
+import (
+	"errors"
+	"fourthfloor/internal/model"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestStore opens a Store in a temporary directory on a fake clock
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "watchlist.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	s.now = func() time.Time { return time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC) }
+	return s, path
+}
+
+// ---- Tests ----
+
+func TestStoreCRUD(t *testing.T) {
+	s, path := openTestStore(t)
+
+	var notified [][]model.WatchlistEntry
+	s.OnChange(func(entries []model.WatchlistEntry) { notified = append(notified, entries) })
+
+	added, err := s.Add(model.WatchlistEntry{ChannelID: "2", Login: "two", Tags: []string{" VODs", "esports", "vods", ""}})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if len(added.Tags) != 2 || added.Tags[0] != "esports" || added.Tags[1] != "vods" || added.AddedAt.IsZero() {
+		t.Errorf("wanted tags normalized and added time set, got %+v", added)
+	}
+	if _, err := s.Add(model.WatchlistEntry{ChannelID: "1"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := s.Add(model.WatchlistEntry{ChannelID: "2"}); !errors.Is(err, ErrAlreadyWatched) {
+		t.Errorf("wanted ErrAlreadyWatched, got %v", err)
+	}
+
+	interval, notes := 300, "weekly roster"
+	updated, err := s.Update("2", model.WatchlistPatch{IntervalSeconds: &interval, Notes: &notes})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.IntervalSeconds != 300 || updated.Notes != notes || len(updated.Tags) != 2 || updated.Login != "two" {
+		t.Errorf("wanted only patched fields changed, got %+v", updated)
+	}
+	if _, err := s.Update("3", model.WatchlistPatch{}); !errors.Is(err, ErrNotWatched) {
+		t.Errorf("wanted ErrNotWatched, got %v", err)
+	}
+
+	if err := s.Remove("1"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := s.Remove("1"); !errors.Is(err, ErrNotWatched) {
+		t.Errorf("wanted ErrNotWatched, got %v", err)
+	}
+	if _, err := s.Get("1"); !errors.Is(err, ErrNotWatched) {
+		t.Errorf("wanted ErrNotWatched, got %v", err)
+	}
+
+	if len(notified) != 4 || len(notified[1]) != 2 || notified[1][0].ChannelID != "1" || len(notified[3]) != 1 {
+		t.Errorf("wanted listeners notified of each change with the sorted watchlist, got %+v", notified)
+	}
+
+	// persisted across reopen
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	got, err := reopened.Get("2")
+	if err != nil || got.IntervalSeconds != 300 || got.Notes != notes || len(reopened.List()) != 1 {
+		t.Errorf("wanted watchlist persisted, got %+v err=%v", reopened.List(), err)
+	}
+
+	// no temporary files left behind
+	files, _ := os.ReadDir(filepath.Dir(path))
+	if len(files) != 1 {
+		t.Errorf("wanted only the watchlist file, got %d files", len(files))
+	}
+}
+
+func TestStoreFailedSaveNotApplied(t *testing.T) {
+	s, path := openTestStore(t)
+	notified := 0
+	s.OnChange(func([]model.WatchlistEntry) { notified++ })
+
+	// the watchlist's directory disappears, so the temporary file cannot be created
+	os.RemoveAll(filepath.Dir(path))
+
+	if _, err := s.Add(model.WatchlistEntry{ChannelID: "1"}); err == nil {
+		t.Fatal("wanted error saving the watchlist")
+	}
+	if len(s.List()) != 0 || notified != 0 {
+		t.Errorf("wanted failed change not applied, got %+v and %d notifications", s.List(), notified)
+	}
+}
+
+func TestOpenCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchlist.json")
+	os.WriteFile(path, []byte("{not json"), 0o600)
+
+	if _, err := Open(path); err == nil {
+		t.Error("wanted error opening a corrupt watchlist")
+	}
+}